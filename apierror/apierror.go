@@ -0,0 +1,109 @@
+// Package apierror is the error body returned by every endpoint, along with the request ID
+// middleware that lets a client's error be matched to the server logs
+package apierror
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Error codes. Most responses use the code for their status, see codes
+const (
+	BadRequest   = "bad_request"
+	InvalidParam = "invalid_param"
+	InvalidQuery = "invalid_query"
+	Unauthorized = "unauthorized"
+	Forbidden    = "forbidden"
+	NotFound     = "not_found"
+	Conflict     = "conflict"
+	Internal     = "internal"
+)
+
+var codes = map[int]string{
+	http.StatusBadRequest:          BadRequest,
+	http.StatusUnauthorized:        Unauthorized,
+	http.StatusForbidden:           Forbidden,
+	http.StatusNotFound:            NotFound,
+	http.StatusConflict:            Conflict,
+	http.StatusInternalServerError: Internal,
+}
+
+const (
+	// header carrying the request ID, taken from the request when the client sets one
+	RequestIDHeader = "X-Request-ID"
+	// gin context key holding the request ID
+	requestIDKey = "requestID"
+)
+
+// client supplied request IDs are only trusted when they look like an ID
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// Error is the body of every error response. Details holds anything that helps explain the
+// error, such as the rejected param, and is left out when empty
+type Error struct {
+	Code       string                 `json:"code"`
+	Message    string                 `json:"message"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	Request_ID string                 `json:"request_id"`
+}
+
+// RequestID gives each request an ID, reusing the X-Request-ID header when it is valid, and
+// echoes it in the response headers
+func RequestID(c *gin.Context) {
+	id := c.GetHeader(RequestIDHeader)
+	if !validRequestID.MatchString(id) {
+		b := make([]byte, 8)
+		rand.Read(b)
+		id = hex.EncodeToString(b)
+	}
+
+	c.Set(requestIDKey, id)
+	c.Header(RequestIDHeader, id)
+
+	c.Next()
+}
+
+// ID returns the request ID set by RequestID
+func ID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// RespondCode aborts the request with an Error
+func RespondCode(c *gin.Context, status int, code string, message string, details gin.H) {
+	c.AbortWithStatusJSON(status, Error{
+		Code:       code,
+		Message:    message,
+		Details:    details,
+		Request_ID: ID(c),
+	})
+}
+
+// Respond aborts the request with an Error using the code for status
+func Respond(c *gin.Context, status int, message string, details gin.H) {
+	code, ok := codes[status]
+	if !ok {
+		code = BadRequest
+		if status >= http.StatusInternalServerError {
+			code = Internal
+		}
+	}
+
+	RespondCode(c, status, code, message, details)
+}
+
+// InternalError logs err against the request ID and responds with a 500. The error itself
+// is not sent, as it can describe the database
+func InternalError(c *gin.Context, err error) {
+	log.Printf("request %v: %v", ID(c), err)
+	Respond(c, http.StatusInternalServerError, "something went wrong, quote the request_id when reporting this", nil)
+}
+
+// NoRoute responds to requests for routes that do not exist
+func NoRoute(c *gin.Context) {
+	Respond(c, http.StatusNotFound, "no route for "+c.Request.Method+" "+c.Request.URL.Path, nil)
+}
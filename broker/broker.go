@@ -0,0 +1,91 @@
+package broker
+
+import (
+	"strconv"
+	"sync"
+)
+
+// Event is pushed to clients subscribed to the topic it was published on
+type Event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// Broker fans published events out to subscribers. MemoryBroker only reaches subscribers
+// in the same process, PostgresBroker reaches every instance sharing the database
+type Broker interface {
+	Publish(topic string, e Event) error
+	// Subscribe returns a channel receiving events for the topics and a func that
+	// unsubscribes and closes the channel
+	Subscribe(topics ...string) (<-chan Event, func())
+}
+
+// Topic for events addressed to a single user
+func UserTopic(userID int) string {
+	return "user:" + strconv.Itoa(userID)
+}
+
+// Topic for changes to a curation
+func CurationTopic(curationID int) string {
+	return "curation:" + strconv.Itoa(curationID)
+}
+
+// events buffered per subscriber before new events are dropped for that subscriber
+const bufferSize = 16
+
+type MemoryBroker struct {
+	mu   sync.RWMutex
+	subs map[string]map[chan Event]struct{}
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		subs: make(map[string]map[chan Event]struct{}),
+	}
+}
+
+// Publish never blocks, subscribers that are not keeping up miss the event
+func (b *MemoryBroker) Publish(topic string, e Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subs[topic] {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe(topics ...string) (<-chan Event, func()) {
+	ch := make(chan Event, bufferSize)
+
+	b.mu.Lock()
+	for _, topic := range topics {
+		if b.subs[topic] == nil {
+			b.subs[topic] = make(map[chan Event]struct{})
+		}
+		b.subs[topic][ch] = struct{}{}
+	}
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			for _, topic := range topics {
+				delete(b.subs[topic], ch)
+				if len(b.subs[topic]) == 0 {
+					delete(b.subs, topic)
+				}
+			}
+			b.mu.Unlock()
+
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe
+}
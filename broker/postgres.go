@@ -0,0 +1,95 @@
+package broker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v4/stdlib"
+	"github.com/pkg/errors"
+)
+
+// postgres channel every instance listens on
+const channel = "at_events"
+
+type notification struct {
+	Topic string `json:"topic"`
+	Event Event  `json:"event"`
+}
+
+// PostgresBroker publishes through pg_notify so that events reach subscribers connected
+// to any instance. Notifications received over LISTEN are handed to a local MemoryBroker
+type PostgresBroker struct {
+	db    *sql.DB
+	local *MemoryBroker
+}
+
+// Starts listening on a dedicated connection from db, which must use the pgx driver.
+// The listener reconnects until ctx is cancelled
+func NewPostgresBroker(ctx context.Context, db *sql.DB) *PostgresBroker {
+	b := &PostgresBroker{
+		db:    db,
+		local: NewMemoryBroker(),
+	}
+
+	go func() {
+		for ctx.Err() == nil {
+			if err := b.listen(ctx); err != nil && ctx.Err() == nil {
+				log.Print(errors.Wrap(err, "postgres broker listener stopped, reconnecting"))
+				time.Sleep(time.Second)
+			}
+		}
+	}()
+
+	return b
+}
+
+func (b *PostgresBroker) listen(ctx context.Context) error {
+	conn, err := b.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "listen "+channel); err != nil {
+		return err
+	}
+
+	return conn.Raw(func(driverConn interface{}) error {
+		pgConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errors.New("database connection is not using the pgx driver")
+		}
+
+		for {
+			n, err := pgConn.Conn().WaitForNotification(ctx)
+			if err != nil {
+				return err
+			}
+
+			var msg notification
+			if err := json.Unmarshal([]byte(n.Payload), &msg); err != nil {
+				log.Print(errors.Wrap(err, "unable to unmarshal notification payload"))
+				continue
+			}
+
+			b.local.Publish(msg.Topic, msg.Event)
+		}
+	})
+}
+
+func (b *PostgresBroker) Publish(topic string, e Event) error {
+	payload, err := json.Marshal(notification{Topic: topic, Event: e})
+	if err != nil {
+		return err
+	}
+
+	_, err = b.db.Exec("select pg_notify($1, $2)", channel, string(payload))
+	return err
+}
+
+func (b *PostgresBroker) Subscribe(topics ...string) (<-chan Event, func()) {
+	return b.local.Subscribe(topics...)
+}
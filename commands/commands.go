@@ -0,0 +1,49 @@
+// Package commands holds the offline jobs run as subcommands of the server binary, e.g.
+// at-be palettes -all. They run after the database is migrated, instead of the web server
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// a subcommand is given the args after its name
+type command func(db *gorm.DB, args []string) error
+
+var commands = map[string]command{
+	"evaluate":        Evaluate,
+	"hashes":          Hashes,
+	"ingest":          Ingest,
+	"palettes":        Palettes,
+	"rankings":        Rankings,
+	"recommendations": Recommendations,
+}
+
+// Names lists the subcommands, sorted
+func Names() []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Run runs the subcommand named by args[0] with the rest of args
+func Run(db *gorm.DB, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("a command is required, one of %v", strings.Join(Names(), ", "))
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q, expected one of %v", args[0], strings.Join(Names(), ", "))
+	}
+
+	return errors.Wrap(cmd(db, args[1:]), args[0])
+}
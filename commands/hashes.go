@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"flag"
+	"image"
+
+	"AT-BE/imaging"
+	"AT-BE/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Hashes computes the perceptual hashes of each artwork's Image_Small and stores them in
+// artwork_hashes for the similar artworks endpoint, which loads them at startup. Only
+// artworks without hashes are processed unless -all is passed
+func Hashes(db *gorm.DB, args []string) error {
+	flags := flag.NewFlagSet("hashes", flag.ContinueOnError)
+	all := flags.Bool("all", false, "recompute hashes that already exist")
+	workers := flags.Int("workers", 4, "images downloaded at once")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	return eachArtworkImage(db, "hashes", "artwork_hashes", *all, *workers, func(artwork models.Artwork, img image.Image) error {
+		hash := imaging.PerceptualHash(img)
+		row := models.ArtworkHashes{
+			Artwork_ID: artwork.ID,
+			P_Hash:     int64(hash.P),
+			D_Hash:     int64(hash.D),
+		}
+
+		return db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "artwork_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"p_hash", "d_hash"}),
+		}).Create(&row).Error
+	})
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"AT-BE/models"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// artworks read from the database at a time
+const imageBatch = 500
+
+var imageClient = &http.Client{Timeout: 30 * time.Second}
+
+// Downloads an image and decodes it as a gif, jpeg or png
+func fetchImage(url string) (image.Image, error) {
+	res, err := imageClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%v returned %v", url, res.Status)
+	}
+
+	img, _, err := image.Decode(res.Body)
+	return img, errors.Wrap(err, url)
+}
+
+// Downloads the Image_Small of each artwork and passes it to process, using workers goroutines.
+// Unless all is set, artworks that already have a row in table, keyed by artwork_id, are
+// skipped. Failures are logged and counted rather than stopping the job, so it can be rerun to
+// retry them
+func eachArtworkImage(db *gorm.DB, name string, table string, all bool, workers int, process func(models.Artwork, image.Image) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	var done, failed int64
+	lastID := 0
+
+	for {
+		query := db.Model(&models.Artwork{}).Select("id, image_small").Where(
+			"id > ? and coalesce(image_small, '') <> ''", lastID)
+		if !all {
+			query = query.Where("not exists (select 1 from " + table + " as t where t.artwork_id = artwork_migrate_artwork.id)")
+		}
+
+		var artworks []models.Artwork
+		if err := query.Order("id").Limit(imageBatch).Find(&artworks).Error; err != nil {
+			return err
+		}
+		if len(artworks) == 0 {
+			break
+		}
+		lastID = artworks[len(artworks)-1].ID
+
+		jobs := make(chan models.Artwork)
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for artwork := range jobs {
+					img, err := fetchImage(artwork.Image_Small)
+					if err == nil {
+						err = process(artwork, img)
+					}
+					if err != nil {
+						log.Printf("artwork %v: %v", artwork.ID, err)
+						atomic.AddInt64(&failed, 1)
+						continue
+					}
+					atomic.AddInt64(&done, 1)
+				}
+			}()
+		}
+
+		for _, artwork := range artworks {
+			jobs <- artwork
+		}
+		close(jobs)
+		wg.Wait()
+
+		log.Printf("%v: %v computed, %v failed, up to artwork %v", name, done, failed, lastID)
+	}
+
+	fmt.Printf("%v: %v computed, %v failed\n", name, done, failed)
+	return nil
+}
@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"AT-BE/ingest"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// Ingest loads museum open data dumps (CSV, a JSON array or JSON lines) into the catalog, e.g.
+//
+//	at-be ingest -sources sources.csv -eras eras.csv -source MET -artists artists.csv -artworks artworks.jsonl
+//
+// Dumps are loaded in the order sources, eras, artists, artworks so that later rows can refer
+// to earlier ones. Artists and artworks are upserted by the -source's IDs for them. Rows that
+// cannot be loaded are written to <dump>.errors.csv, in -errors when it is set.
+//
+// With -adapter the -artworks dump is a museum's own format, e.g. at-be ingest -adapter met
+// -artworks MetObjects.csv, see ingest.Adapters. Its artists are loaded along with the works
+// and the museum is added as a source if needed
+func Ingest(db *gorm.DB, args []string) error {
+	flags := flag.NewFlagSet("ingest", flag.ContinueOnError)
+	source := flags.String("source", "", "abbreviation of the source the artists and artworks come from")
+	sources := flags.String("sources", "", "dump of sources, with abbreviation and name")
+	eras := flags.String("eras", "", "dump of eras, with name")
+	artists := flags.String("artists", "", "dump of artists, with external_id and name")
+	artworks := flags.String("artworks", "", "dump of artworks, with external_id, title and artist_external_id")
+	adapter := flags.String("adapter", "", "format of the artworks dump, one of "+strings.Join(ingest.AdapterNames(), ", "))
+	errorDir := flags.String("errors", "", "directory for the error files, next to each dump by default")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *sources == "" && *eras == "" && *artists == "" && *artworks == "" {
+		return errors.New("nothing to ingest, pass at least one of -sources, -eras, -artists or -artworks")
+	}
+	in := ingest.New(db, *errorDir)
+	loadArtworks := in.Artworks
+
+	if *adapter != "" {
+		sourceAdapter, ok := ingest.Adapters[*adapter]
+		if !ok {
+			return fmt.Errorf("unknown adapter %q, expected one of %v", *adapter, strings.Join(ingest.AdapterNames(), ", "))
+		}
+		if *source != "" || *artists != "" {
+			return errors.New("-source and -artists are taken from the -adapter dump")
+		}
+
+		loadArtworks = func(path string) (ingest.Counts, error) {
+			return in.Adapted(sourceAdapter, path)
+		}
+	} else if (*artists != "" || *artworks != "") && *source == "" {
+		return errors.New("-source is required to ingest artists or artworks")
+	}
+
+	steps := []struct {
+		name string
+		path string
+		load func(string) (ingest.Counts, error)
+	}{
+		{"sources", *sources, in.Sources},
+		{"eras", *eras, in.Eras},
+		{"artists", *artists, in.Artists},
+		{"artworks", *artworks, loadArtworks},
+	}
+
+	for _, step := range steps {
+		// the source may have been loaded by the sources step
+		if step.name == "artists" && *source != "" {
+			if err := in.UseSource(*source); err != nil {
+				return err
+			}
+		}
+		if step.path == "" {
+			continue
+		}
+
+		counts, err := step.load(step.path)
+		if err != nil {
+			return errors.Wrap(err, step.path)
+		}
+
+		log.Printf("ingest: %v: %v inserted, %v updated, %v rejected", step.name, counts.Inserted, counts.Updated, counts.Rejected)
+		if counts.Errors != "" {
+			log.Printf("ingest: %v: rejected rows written to %v", step.name, counts.Errors)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"flag"
+	"image"
+
+	"AT-BE/imaging"
+	"AT-BE/models"
+
+	"gorm.io/gorm"
+)
+
+// colours kept per artwork
+const paletteSize = 5
+
+// Replaces the stored palette of an artwork
+func savePalette(db *gorm.DB, artworkID int, palette []imaging.Swatch) error {
+	colours := make([]models.ArtworkColours, len(palette))
+	for i, swatch := range palette {
+		colours[i] = models.ArtworkColours{
+			Artwork_ID: artworkID,
+			Hex:        imaging.Hex(swatch.Colour),
+			Lab_L:      swatch.Lab.L,
+			Lab_A:      swatch.Lab.A,
+			Lab_B:      swatch.Lab.B,
+			Weight:     swatch.Weight,
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("artwork_id = ?", artworkID).Delete(&models.ArtworkColours{}).Error; err != nil {
+			return err
+		}
+		if len(colours) == 0 {
+			return nil
+		}
+
+		return tx.Create(&colours).Error
+	})
+}
+
+// Palettes computes the dominant colours of each artwork's Image_Small and stores them in
+// artwork_colours for colour search. Only artworks without a palette are processed unless
+// -all is passed
+func Palettes(db *gorm.DB, args []string) error {
+	flags := flag.NewFlagSet("palettes", flag.ContinueOnError)
+	all := flags.Bool("all", false, "recompute palettes that already exist")
+	workers := flags.Int("workers", 4, "images downloaded at once")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	return eachArtworkImage(db, "palettes", "artwork_colours", *all, *workers, func(artwork models.Artwork, img image.Image) error {
+		return savePalette(db, artwork.ID, imaging.Palette(img, paletteSize))
+	})
+}
@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"AT-BE/rankings"
+
+	"gorm.io/gorm"
+)
+
+// Rankings recomputes the popularity and trending rankings once, for running from a scheduler
+// instead of, or as well as, the server's own refresh loop
+func Rankings(db *gorm.DB, args []string) error {
+	flags := flag.NewFlagSet("rankings", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if err := rankings.Refresh(db, start); err != nil {
+		return err
+	}
+	log.Printf("rankings: refreshed in %v", time.Since(start).Round(time.Millisecond))
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"flag"
+	"log"
+	"math/rand"
+
+	"AT-BE/models"
+	"AT-BE/recommend"
+
+	"gorm.io/gorm"
+)
+
+// rows written per insert when saving the model
+const neighbourBatchSize = 1000
+
+// Reads every current artwork like, most recent first so that recommend.MaxUserLikes keeps a
+// user's latest likes
+func loadLikes(db *gorm.DB) (recommend.Likes, error) {
+	rows, err := db.Model(&models.ArtworkLikes{}).Select("user_id, artwork_id").Where(
+		"artwork_likes.like = true").Order("updated_at desc").Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	likes := recommend.Likes{}
+	for rows.Next() {
+		var user, artwork int
+		if err := rows.Scan(&user, &artwork); err != nil {
+			return nil, err
+		}
+		likes[user] = append(likes[user], artwork)
+	}
+
+	return likes, rows.Err()
+}
+
+// Recommendations builds the item-item model from artwork likes and replaces artwork_neighbours
+// with it. Run it periodically, e.g. nightly, as the recommendations endpoint only reads the
+// last model built
+func Recommendations(db *gorm.DB, args []string) error {
+	flags := flag.NewFlagSet("recommendations", flag.ContinueOnError)
+	neighbours := flags.Int("neighbours", 50, "similar artworks kept per artwork")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	likes, err := loadLikes(db)
+	if err != nil {
+		return err
+	}
+
+	model := recommend.Build(likes, *neighbours)
+
+	var rows []models.ArtworkNeighbours
+	for id, list := range model {
+		for _, n := range list {
+			rows = append(rows, models.ArtworkNeighbours{Artwork_ID: id, Neighbour_ID: n.ID, Score: n.Score})
+		}
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("delete from artwork_neighbours").Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		return tx.CreateInBatches(&rows, neighbourBatchSize).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("recommendations: %v users, %v artworks, %v neighbours", len(likes), len(model), len(rows))
+
+	return nil
+}
+
+// Evaluate holds out part of each user's likes, builds a model from the rest and reports how
+// many of the held out likes appear in the top k recommendations (precision@k and recall@k).
+// Nothing is written, so it can be run against production data to tune -neighbours
+func Evaluate(db *gorm.DB, args []string) error {
+	flags := flag.NewFlagSet("evaluate", flag.ContinueOnError)
+	k := flags.Int("k", 10, "recommendations per user")
+	holdout := flags.Float64("holdout", 0.2, "fraction of each user's likes held out")
+	neighbours := flags.Int("neighbours", 50, "similar artworks kept per artwork")
+	seed := flags.Int64("seed", 1, "seed for the held out split")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	likes, err := loadLikes(db)
+	if err != nil {
+		return err
+	}
+
+	train, test := recommend.Split(likes, *holdout, rand.New(rand.NewSource(*seed)))
+	eval := recommend.Evaluate(train, test, *k, *neighbours)
+
+	log.Printf("evaluate: %v users held out, %v given recommendations", eval.Users, eval.Covered)
+	log.Printf("evaluate: precision@%v %.4f, recall@%v %.4f", *k, eval.Precision, *k, eval.Recall)
+
+	return nil
+}
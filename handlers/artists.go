@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"AT-BE/apierror"
+	"AT-BE/models"
+	"AT-BE/search"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// first letter of an artist's name with accents removed, or # when it is not a letter
+const nameLetter = "case when f_unaccent(ar.name) ~* '^[a-z]' then upper(left(f_unaccent(ar.name), 1)) else '#' end"
+
+// works per artist, joined to the artist listing
+const workCountJoin = "left join (select artist_id, count(*) as works from artwork_migrate_artwork " +
+	"group by artist_id) as w on w.artist_id = ar.id"
+
+// Applies the era, gender and q (part of the name, accents ignored) params to a query over
+// artwork_migrate_artist aliased as ar
+func filterArtists(c *gin.Context, query *gorm.DB) *gorm.DB {
+	if era := c.Query("era"); era != "" {
+		query = query.Where("ar.era = ?", era)
+	}
+	if gender := c.Query("gender"); gender != "" {
+		query = query.Where("lower(ar.gender) = lower(?)", gender)
+	}
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		query = query.Where("f_unaccent(ar.name) ilike f_unaccent(?)", "%"+search.EscapeLike(q)+"%")
+	}
+
+	return query
+}
+
+// Lists artists alphabetically, paginated with the page (row offset) and limit params and
+// filtered by era, gender and q, see filterArtists. The letter param (A to Z, or # for names
+// that do not start with a letter) picks one bucket of the alphabetical index, which is
+// returned with the number of artists under each letter
+func GetArtists(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, set, ok := intQuery(c, "limit")
+		if !ok {
+			return
+		}
+		if !set || limit <= 0 {
+			limit = defaultBrowseLimit
+		}
+		if limit > maxBrowseLimit {
+			limit = maxBrowseLimit
+		}
+
+		letter := strings.ToUpper(c.Query("letter"))
+		if letter != "" && letter != "#" && (len(letter) != 1 || letter[0] < 'A' || letter[0] > 'Z') {
+			apierror.RespondCode(c, http.StatusBadRequest, apierror.InvalidParam, "letter must be a single letter from A to Z, or #", gin.H{
+				"request_param": c.Query("letter"),
+			})
+
+			return
+		}
+
+		page := pageParam(c)
+		filtered := filterArtists(c, db.Table("artwork_migrate_artist as ar"))
+
+		list := models.ArtistList{
+			Artists:  []models.ArtistSummary{},
+			Index:    []models.FacetCount{},
+			NextPage: page + limit,
+		}
+
+		err := filtered.Session(&gorm.Session{}).Select(nameLetter + " as value, count(*) as count").Group(
+			nameLetter).Order("value").Scan(&list.Index).Error
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		query := filtered.Session(&gorm.Session{})
+		if letter != "" {
+			query = query.Where(nameLetter+" = ?", letter)
+		}
+
+		err = query.Session(&gorm.Session{}).Count(&list.Total).Error
+		if err == nil {
+			err = query.Select("ar.*, coalesce(w.works, 0) as work_count").Joins(workCountJoin).Order(
+				"f_unaccent(ar.name), ar.id").Offset(page).Limit(limit).Scan(&list.Artists).Error
+		}
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, list)
+	}
+}
+
+// Returns the artist in the id param with a page of their works, oldest first, paginated with
+// the page (row offset) and limit params. The work count, the span of years and the sources
+// holding their works cover all of the artist's works rather than the page
+func GetArtist(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c, "id")
+		if !ok {
+			return
+		}
+
+		limit, set, ok := intQuery(c, "limit")
+		if !ok {
+			return
+		}
+		if !set || limit <= 0 {
+			limit = defaultBrowseLimit
+		}
+		if limit > maxBrowseLimit {
+			limit = maxBrowseLimit
+		}
+
+		page := pageParam(c)
+
+		detail := models.ArtistDetail{
+			Works:    []models.Artwork{},
+			Sources:  []models.SourceCount{},
+			NextPage: page + limit,
+		}
+
+		if err := db.Take(&detail.Artist, id).Error; err != nil {
+			lookupFailed(c, err, "artist could not be found")
+			return
+		}
+
+		works := db.Table("artwork_migrate_artwork as a").Where("a.artist_id = ?", id)
+
+		var span struct {
+			Work_Count int64
+			First_Year *int
+			Last_Year  *int
+		}
+		err := works.Session(&gorm.Session{}).Select(
+			"count(*) as work_count, min(" + releaseYear + ") as first_year, max(" + releaseYear + ") as last_year").Scan(&span).Error
+		detail.Work_Count, detail.First_Year, detail.Last_Year = span.Work_Count, span.First_Year, span.Last_Year
+		if err == nil {
+			err = works.Session(&gorm.Session{}).Select("a.*").Order(
+				releaseYear + " nulls last, a.id").Offset(page).Limit(limit).Scan(&detail.Works).Error
+		}
+		if err == nil {
+			err = works.Session(&gorm.Session{}).Select("src.*, count(*) as work_count").Joins(
+				"join artwork_migrate_source as src on src.id = a.source_id").Group(
+				"src.id").Order("work_count desc, src.source_name").Scan(&detail.Sources).Error
+		}
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, detail)
+	}
+}
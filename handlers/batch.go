@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"AT-BE/apierror"
+	"AT-BE/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// most artworks in one batch lookup when the batchlimit env variable is not set
+const defaultBatchLimit = 100
+
+// Reads the batch size cap from the batchlimit env variable
+func batchLimit() int {
+	limit, err := strconv.Atoi(os.Getenv("batchlimit"))
+	if err != nil || limit <= 0 {
+		return defaultBatchLimit
+	}
+
+	return limit
+}
+
+// Looks up ids with one query and responds with the artworks in the same order, listing the
+// IDs that do not exist under missing. Repeated IDs are only returned once
+func artworkBatch(db *gorm.DB, c *gin.Context, ids []int) {
+	unique := make([]int, 0, len(ids))
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if id <= 0 {
+			apierror.RespondCode(c, http.StatusBadRequest, apierror.InvalidParam, "ids must be positive integers", gin.H{
+				"request_param": id,
+			})
+
+			return
+		}
+
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	if len(unique) == 0 {
+		apierror.RespondCode(c, http.StatusBadRequest, apierror.InvalidParam, "at least one ID is required", nil)
+		return
+	}
+
+	if limit := batchLimit(); len(unique) > limit {
+		apierror.RespondCode(c, http.StatusBadRequest, apierror.InvalidParam, "too many IDs", gin.H{
+			"max":       limit,
+			"requested": len(unique),
+		})
+
+		return
+	}
+
+	var rows []models.Searches
+	if err := db.Table("searches").Where("searches.\"ID\" in ?", unique).Find(&rows).Error; err != nil {
+		apierror.InternalError(c, err)
+		return
+	}
+
+	byID := make(map[string]models.Searches, len(rows))
+	for _, row := range rows {
+		byID[row.ID] = row
+	}
+
+	batch := models.ArtworkBatch{
+		Artworks: make([]models.Searches, 0, len(rows)),
+		Missing:  []int{},
+	}
+	for _, id := range unique {
+		if row, ok := byID[strconv.Itoa(id)]; ok {
+			batch.Artworks = append(batch.Artworks, row)
+		} else {
+			batch.Missing = append(batch.Missing, id)
+		}
+	}
+
+	c.JSON(http.StatusOK, batch)
+}
+
+// Returns the artworks in the comma separated ids param (e.g. ?ids=1,2,3), see artworkBatch.
+// At most batchlimit IDs can be asked for at once
+func GetArtworkBatch(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		param := c.Query("ids")
+
+		var ids []int
+		for _, part := range strings.Split(param, ",") {
+			if strings.TrimSpace(part) == "" {
+				continue
+			}
+
+			id, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				apierror.RespondCode(c, http.StatusBadRequest, apierror.InvalidParam, "ids must be a comma separated list of IDs", gin.H{
+					"request_param": param,
+				})
+
+				return
+			}
+			ids = append(ids, id)
+		}
+
+		artworkBatch(db, c, ids)
+	}
+}
+
+// Same as GetArtworkBatch with the IDs in the request body, for lists too long for a URL
+func PostArtworkBatch(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.ArtworkBatchReq
+		if err := req.ProcessReq(c.Request); err != nil {
+			apierror.Respond(c, http.StatusBadRequest, errors.Wrap(err, "unable to read request.body").Error(), nil)
+			return
+		}
+
+		artworkBatch(db, c, req.IDs)
+	}
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"AT-BE/apierror"
+	"AT-BE/models"
+	"AT-BE/search"
+	"AT-BE/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultBrowseLimit = 20
+	maxBrowseLimit     = 100
+)
+
+// first four digit year in Date_of_Release, which is free text such as "c. 1660-1665"
+const releaseYear = "cast(substring(a.date_of_release from '[0-9]{4}') as int)"
+
+// sort expressions for the sort param along with the type used to compare cursor values
+var artworkSorts = map[string]struct {
+	expr string
+	cast string
+}{
+	"id":            {"a.id", "bigint"},
+	"title":         {"coalesce(a.title, '')", "text"},
+	"date":          {"coalesce(" + releaseYear + ", 0)", "int"},
+	"last_modified": {"coalesce(a.last_modified, 'epoch'::timestamptz)", "timestamptz"},
+	"popularity":    {"coalesce(pop.likes, 0)", "bigint"},
+}
+
+// likes per artwork, joined when sorting by popularity
+const popularityJoin = "left join (select artwork_id, count(*) as likes from artwork_likes " +
+	"where artwork_likes.like = true and artwork_likes.deleted_at is null group by artwork_id) as pop on pop.artwork_id = a.id"
+
+type artworkRow struct {
+	models.Artwork
+	Sort_Value string
+}
+
+// Reads an optional integer query param, responding with a 400 when it is malformed
+func intQuery(c *gin.Context, name string) (int, bool, bool) {
+	param := c.Query(name)
+	if param == "" {
+		return 0, false, true
+	}
+
+	v, err := strconv.Atoi(param)
+	if err != nil {
+		apierror.RespondCode(c, http.StatusBadRequest, apierror.InvalidParam, name+" must be an integer", gin.H{
+			"request_param": param,
+		})
+
+		return 0, false, false
+	}
+
+	return v, true, true
+}
+
+// Applies the artist_id, source_id, era, medium, nationality, culture, gender, date_from and
+// date_to query params to a query over artwork_migrate_artwork aliased as a
+func filterArtworks(c *gin.Context, query *gorm.DB) (*gorm.DB, bool) {
+	for _, col := range []string{"artist_id", "source_id"} {
+		v, set, ok := intQuery(c, col)
+		if !ok {
+			return nil, false
+		}
+		if set {
+			query = query.Where("a."+col+" = ?", v)
+		}
+	}
+
+	for _, col := range []string{"medium", "nationality", "culture", "gender"} {
+		if v := c.Query(col); v != "" {
+			query = query.Where("a."+col+" ilike ?", "%"+search.EscapeLike(v)+"%")
+		}
+	}
+
+	if era := c.Query("era"); era != "" {
+		query = query.Where("a.artist_id in (select ar.id from artwork_migrate_artist as ar where ar.era = ?)", era)
+	}
+
+	from, fromSet, ok := intQuery(c, "date_from")
+	if !ok {
+		return nil, false
+	}
+	to, toSet, ok := intQuery(c, "date_to")
+	if !ok {
+		return nil, false
+	}
+
+	if fromSet {
+		query = query.Where(releaseYear+" >= ?", from)
+	}
+	if toSet {
+		query = query.Where(releaseYear+" <= ?", to)
+	}
+
+	return query, true
+}
+
+// Browses artworks with optional filters, see filterArtworks. Results are ordered by the sort
+// param (id, title, date, last_modified or popularity) in the order param direction (asc or desc)
+// and paginated with next_cursor. last_id is still accepted in place of a cursor when sorting by id
+func GetArtworks(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, set, ok := intQuery(c, "limit")
+		if !ok {
+			return
+		}
+		if !set || limit <= 0 {
+			limit = defaultBrowseLimit
+		}
+		if limit > maxBrowseLimit {
+			limit = maxBrowseLimit
+		}
+
+		sortKey := c.DefaultQuery("sort", "id")
+		sort, known := artworkSorts[sortKey]
+		if !known {
+			apierror.RespondCode(c, http.StatusBadRequest, apierror.InvalidParam, "sort must be one of id, title, date, last_modified or popularity", gin.H{
+				"request_param": sortKey,
+			})
+
+			return
+		}
+
+		direction := strings.ToLower(c.DefaultQuery("order", "asc"))
+		if direction != "asc" && direction != "desc" {
+			apierror.RespondCode(c, http.StatusBadRequest, apierror.InvalidParam, "order must be asc or desc", gin.H{
+				"request_param": direction,
+			})
+
+			return
+		}
+
+		query := db.Table("artwork_migrate_artwork as a").Select("a.*, cast(" + sort.expr + " as text) as sort_value")
+		if sortKey == "popularity" {
+			query = query.Joins(popularityJoin)
+		}
+
+		query, ok = filterArtworks(c, query)
+		if !ok {
+			return
+		}
+
+		comparison := ">"
+		if direction == "desc" {
+			comparison = "<"
+		}
+
+		if cursor := c.Query("cursor"); cursor != "" {
+			value, id, err := utils.DecodeCursor(cursor)
+			if err != nil {
+				apierror.RespondCode(c, http.StatusBadRequest, apierror.InvalidParam, err.Error(), gin.H{
+					"request_param": cursor,
+				})
+
+				return
+			}
+
+			query = query.Where("("+sort.expr+", a.id) "+comparison+" (cast(? as "+sort.cast+"), ?)", value, id)
+		} else if sortKey == "id" {
+			lastID, set, ok := intQuery(c, "last_id")
+			if !ok {
+				return
+			}
+			if set {
+				query = query.Where("a.id "+comparison+" ?", lastID)
+			}
+		}
+
+		var rows []artworkRow
+		err := query.Order(sort.expr + " " + direction + ", a.id " + direction).Limit(limit).Scan(&rows).Error
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		page := models.ArtworkPage{Artworks: make([]models.Artwork, len(rows))}
+		for i, row := range rows {
+			page.Artworks[i] = row.Artwork
+		}
+
+		if len(rows) == limit {
+			last := rows[len(rows)-1]
+			page.NextCursor = utils.EncodeCursor(last.Sort_Value, last.ID)
+		}
+
+		c.JSON(http.StatusOK, page)
+	}
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"net/http"
+
+	"AT-BE/apierror"
+	"AT-BE/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// artists returned with an era or source
+const topArtistsSize = 5
+
+// Fills collection from works, a query over artwork_migrate_artwork aliased as a. The page and
+// limit params paginate the works, which are ordered by ID. Returns false when a 400 has been
+// sent for a malformed limit param
+func loadCollection(c *gin.Context, works *gorm.DB, collection *models.Collection) (bool, error) {
+	limit, set, ok := intQuery(c, "limit")
+	if !ok {
+		return false, nil
+	}
+	if !set || limit <= 0 {
+		limit = defaultBrowseLimit
+	}
+	if limit > maxBrowseLimit {
+		limit = maxBrowseLimit
+	}
+
+	page := pageParam(c)
+	collection.Works = []models.Artwork{}
+	collection.Top_Artists = []models.ArtistSummary{}
+	collection.NextPage = page + limit
+
+	if err := works.Session(&gorm.Session{}).Count(&collection.Work_Count).Error; err != nil {
+		return true, err
+	}
+
+	err := works.Session(&gorm.Session{}).Select("a.*").Order("a.id").Offset(page).Limit(limit).Scan(&collection.Works).Error
+	if err != nil {
+		return true, err
+	}
+
+	err = works.Session(&gorm.Session{}).Select("top.*, count(*) as work_count").Joins(
+		"join artwork_migrate_artist as top on top.id = a.artist_id").Group(
+		"top.id").Order("work_count desc, top.name").Limit(topArtistsSize).Scan(&collection.Top_Artists).Error
+	if err != nil {
+		return true, err
+	}
+
+	var representative models.Artwork
+	result := works.Session(&gorm.Session{}).Select("a.*").Joins(popularityJoin).Where(
+		"coalesce(a.image_small, '') <> ''").Order("coalesce(pop.likes, 0) desc, a.id").Limit(1).Scan(&representative)
+	if result.Error != nil {
+		return true, result.Error
+	}
+	if result.RowsAffected > 0 {
+		collection.Representative = &representative
+	}
+
+	return true, nil
+}
+
+// Lists every era by name with the number of works by its artists
+func GetEras(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		eras := []models.EraCount{}
+		err := db.Table("artwork_migrate_era as e").Select("e.*, count(a.id) as work_count").Joins(
+			"left join artwork_migrate_artist as ar on ar.era = e.era_name").Joins(
+			"left join artwork_migrate_artwork as a on a.artist_id = ar.id").Group(
+			"e.id").Order("e.era_name").Scan(&eras).Error
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, eras)
+	}
+}
+
+// Returns the era in the id param with its works, paginated with the page (row offset) and
+// limit params, along with its top artists and a representative image, see models.Collection
+func GetEra(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c, "id")
+		if !ok {
+			return
+		}
+
+		var detail models.EraDetail
+		if err := db.Take(&detail.Era, id).Error; err != nil {
+			lookupFailed(c, err, "era could not be found")
+			return
+		}
+
+		works := db.Table("artwork_migrate_artwork as a").Joins(
+			"join artwork_migrate_artist as ar on ar.id = a.artist_id").Where("ar.era = ?", detail.Era_Name)
+
+		ok, err := loadCollection(c, works, &detail.Collection)
+		if !ok {
+			return
+		}
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, detail)
+	}
+}
+
+// Lists every source by name with the number of works it holds
+func GetSources(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sources := []models.SourceCount{}
+		err := db.Table("artwork_migrate_source as src").Select("src.*, count(a.id) as work_count").Joins(
+			"left join artwork_migrate_artwork as a on a.source_id = src.id").Group(
+			"src.id").Order("src.source_name").Scan(&sources).Error
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, sources)
+	}
+}
+
+// Returns the source in the id param with its works, paginated with the page (row offset) and
+// limit params, along with its top artists and a representative image, see models.Collection
+func GetSource(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c, "id")
+		if !ok {
+			return
+		}
+
+		var detail models.SourceDetail
+		if err := db.Take(&detail.Source, id).Error; err != nil {
+			lookupFailed(c, err, "source could not be found")
+			return
+		}
+
+		works := db.Table("artwork_migrate_artwork as a").Where("a.source_id = ?", id)
+
+		ok, err := loadCollection(c, works, &detail.Collection)
+		if !ok {
+			return
+		}
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, detail)
+	}
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"AT-BE/apierror"
+	"AT-BE/broker"
+	"AT-BE/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// number of events returned per page of the feed
+const feedSize = 20
+
+// Writes an activity row for the feed. Failures are logged rather than returned so that
+// the request which triggered the activity still succeeds
+func recordActivity(db *gorm.DB, activity models.Activity) {
+	if err := db.Create(&activity).Error; err != nil {
+		log.Print(errors.Wrapf(err, "unable to record %v activity", activity.Verb))
+	}
+}
+
+// Returns the caller's feed, newest first. An event is relevant when it was done by a user
+// the caller follows, happened to a curation they follow, added artwork to a curation they
+// liked, created a curation containing an artwork they liked or liked one of their curations.
+// Pass next_cursor from the previous response as the cursor param to get the next page
+func GetFeed(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authID := c.GetInt("authID")
+
+		query := db.Table("activities as a").Select("a.*").Joins(
+			"left join curations as cur on cur.id = a.curation_id").Where(
+			"a.deleted_at is null and a.actor_id <> ?", authID).Where(
+			"a.curation_id = 0 or cur.private = false or cur.user_id = ?", authID).Where(
+			"a.actor_id not in (select b.blocked_id from blocks as b where b.blocker_id = ? and b.deleted_at is null)", authID).Where(
+			"a.owner_id not in (select b.blocked_id from blocks as b where b.blocker_id = ? and b.deleted_at is null)", authID).Where(
+			db.Where("a.actor_id in (select f.followed_id from follows as f where f.follower_id = ?)", authID).Or(
+				"a.curation_id in (select f.curation_id from follows as f where f.follower_id = ? and f.curation_id <> 0)", authID).Or(
+				"a.verb = ? and a.curation_id in (select cl.curation_id from curation_likes as cl where cl.user_id = ? and cl.like = true)", models.VerbCurationArtworkAdded, authID).Or(
+				"a.verb = ? and a.artwork_id in (select al.artwork_id from artwork_likes as al where al.user_id = ? and al.like = true)", models.VerbCurationCreated, authID).Or(
+				"a.verb = ? and a.owner_id = ?", models.VerbCurationLiked, authID))
+
+		if cursor := c.Query("cursor"); cursor != "" {
+			id, err := strconv.Atoi(cursor)
+			if err != nil {
+				apierror.RespondCode(c, http.StatusBadRequest, apierror.InvalidParam, "cursor must be an event ID", gin.H{
+					"request_param": cursor,
+				})
+
+				return
+			}
+
+			query = query.Where("a.id < ?", id)
+		}
+
+		var feed models.Feed
+		if err := query.Order("a.id desc").Limit(feedSize).Scan(&feed.Events).Error; err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		if len(feed.Events) == feedSize {
+			feed.NextCursor = feed.Events[len(feed.Events)-1].ID
+		}
+
+		c.JSON(http.StatusOK, feed)
+	}
+}
+
+// Likes or unlikes the curation in ItemID for the logged in user, creating the CurationLikes
+// row on the first like
+func CurationLike(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authID := c.GetInt("authID")
+
+		var reqData models.LikeReqData
+		if err := reqData.ProcessReq(c.Request); err != nil {
+			apierror.Respond(c, http.StatusBadRequest, errors.Wrap(err, "unable to read request.body").Error(), nil)
+			log.Print(err)
+
+			return
+		}
+
+		cID, err := strconv.Atoi(reqData.ItemID)
+		if err != nil {
+			apierror.RespondCode(c, http.StatusBadRequest, apierror.InvalidParam, "itemID must be a curation ID", gin.H{
+				"reqData": reqData.ToString(),
+			})
+			log.Print(err)
+
+			return
+		}
+
+		cur, err := visibleCuration(db, cID, authID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				apierror.Respond(c, http.StatusNotFound, "curation could not be found", nil)
+			} else {
+				apierror.InternalError(c, err)
+			}
+
+			return
+		}
+
+		blocked, err := isBlocked(db, cur.User_ID, authID)
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		if blocked {
+			apierror.Respond(c, http.StatusForbidden, "the curation's owner has blocked you", nil)
+
+			return
+		}
+
+		curationLike := models.CurationLikes{Curation_ID: cur.ID, User_ID: authID}
+		result := db.Where(&curationLike, "curation_id", "user_id").FirstOrInit(&curationLike)
+		if result.Error != nil {
+			apierror.InternalError(c, result.Error)
+
+			return
+		}
+
+		newlyLiked := reqData.LikeStatus && !curationLike.Like
+		curationLike.Like = reqData.LikeStatus
+		if err := db.Save(&curationLike).Error; err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		if newlyLiked && cur.User_ID != authID {
+			recordActivity(db, models.Activity{
+				Actor_ID:    authID,
+				Verb:        models.VerbCurationLiked,
+				Curation_ID: cur.ID,
+				Owner_ID:    cur.User_ID,
+			})
+
+			notify(db, models.Notifications{
+				User_ID:     cur.User_ID,
+				Actor_ID:    authID,
+				Category:    models.NotifyCurationLiked,
+				Curation_ID: cur.ID,
+			})
+		}
+
+		publish(broker.CurationTopic(cID), "curation_like_updated", curationLike)
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "successfully updated",
+			"like":    curationLike,
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"AT-BE/apierror"
+	"AT-BE/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// number of rows returned per page by the list endpoints
+const pageSize = 10
+
+// Parses a numeric path parameter, responding with a 400 when it is malformed
+func paramID(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil || id <= 0 {
+		apierror.RespondCode(c, http.StatusBadRequest, apierror.InvalidParam, name+" must be a positive integer", gin.H{
+			"request_param": c.Param(name),
+		})
+
+		return 0, false
+	}
+
+	return id, true
+}
+
+// Reads the page query param as a row offset, defaulting to the first page
+func pageParam(c *gin.Context) int {
+	return offsetParam(c, "page")
+}
+
+// Reads the offset in the named query param, defaulting to 0 when it is missing or invalid
+func offsetParam(c *gin.Context, name string) int {
+	page, err := strconv.Atoi(c.Query(name))
+	if err != nil || page < 0 {
+		return 0
+	}
+
+	return page
+}
+
+// Checks whether a row with the given ID exists in the model's table
+func recordExists(db *gorm.DB, model interface{}, id int) (bool, error) {
+	var count int64
+	if err := db.Model(model).Where("id = ?", id).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// Responds to a failed lookup of the row in the id param with a 404 when it is missing, or a 500
+func lookupFailed(c *gin.Context, err error, message string) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		apierror.Respond(c, http.StatusNotFound, message, gin.H{
+			"request_param": c.Param("id"),
+		})
+	} else {
+		apierror.InternalError(c, err)
+	}
+}
+
+// Loads a curation the caller is allowed to see. Private curations are only visible to their
+// owner, and curations by users the caller has blocked are hidden
+func visibleCuration(db *gorm.DB, id int, authID int) (models.CurationSummary, error) {
+	var cur models.CurationSummary
+	result := db.Model(&models.Curations{}).Where("id = ?", id).Take(&cur)
+	if result.Error != nil {
+		return cur, result.Error
+	}
+
+	if cur.Private && cur.User_ID != authID {
+		return cur, gorm.ErrRecordNotFound
+	}
+
+	blocked, err := isBlocked(db, authID, cur.User_ID)
+	if err != nil {
+		return cur, err
+	}
+
+	if blocked {
+		return cur, gorm.ErrRecordNotFound
+	}
+
+	return cur, nil
+}
+
+func FollowUser(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authID := c.GetInt("authID")
+		id, ok := paramID(c, "id")
+		if !ok {
+			return
+		}
+
+		if id == authID {
+			apierror.Respond(c, http.StatusBadRequest, "users cannot follow themselves", nil)
+
+			return
+		}
+
+		exists, err := recordExists(db, &models.Users{}, id)
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		if !exists {
+			apierror.Respond(c, http.StatusNotFound, "user could not be found", nil)
+
+			return
+		}
+
+		blocked, err := isBlocked(db, id, authID)
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		if blocked {
+			apierror.Respond(c, http.StatusForbidden, "user has blocked you", nil)
+
+			return
+		}
+
+		follow := models.Follows{Follower_ID: authID, Followed_ID: id}
+		result := db.Where(&follow, "follower_id", "followed_id", "curation_id").FirstOrCreate(&follow)
+		if result.Error != nil {
+			apierror.InternalError(c, result.Error)
+
+			return
+		}
+
+		if result.RowsAffected > 0 {
+			notify(db, models.Notifications{
+				User_ID:  id,
+				Actor_ID: authID,
+				Category: models.NotifyNewFollower,
+			})
+		}
+
+		c.JSON(http.StatusCreated, follow)
+	}
+}
+
+func UnfollowUser(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authID := c.GetInt("authID")
+		id, ok := paramID(c, "id")
+		if !ok {
+			return
+		}
+
+		result := db.Unscoped().Where("follower_id = ? and followed_id = ?", authID, id).Delete(&models.Follows{})
+		if result.Error != nil {
+			apierror.InternalError(c, result.Error)
+
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"message": "user unfollowed",
+		})
+	}
+}
+
+func FollowCuration(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authID := c.GetInt("authID")
+		id, ok := paramID(c, "id")
+		if !ok {
+			return
+		}
+
+		cur, err := visibleCuration(db, id, authID)
+		if err != nil {
+			lookupFailed(c, err, "curation could not be found")
+			return
+		}
+
+		if cur.Private {
+			apierror.Respond(c, http.StatusBadRequest, "private curations cannot be followed", nil)
+
+			return
+		}
+
+		follow := models.Follows{Follower_ID: authID, Curation_ID: cur.ID}
+		result := db.Where(&follow, "follower_id", "followed_id", "curation_id").FirstOrCreate(&follow)
+		if result.Error != nil {
+			apierror.InternalError(c, result.Error)
+
+			return
+		}
+
+		c.JSON(http.StatusCreated, follow)
+	}
+}
+
+func UnfollowCuration(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authID := c.GetInt("authID")
+		id, ok := paramID(c, "id")
+		if !ok {
+			return
+		}
+
+		result := db.Unscoped().Where("follower_id = ? and curation_id = ?", authID, id).Delete(&models.Follows{})
+		if result.Error != nil {
+			apierror.InternalError(c, result.Error)
+
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"message": "curation unfollowed",
+		})
+	}
+}
+
+// Lists the users following the user in the id param
+func GetFollowers(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c, "id")
+		if !ok {
+			return
+		}
+
+		page := pageParam(c)
+		followers := db.Table("follows").Where("follows.followed_id = ? and follows.deleted_at is null", id).Session(&gorm.Session{})
+
+		var list models.FollowList
+		if err := followers.Count(&list.Count).Error; err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		err := followers.Select("users.id, users.username").Joins(
+			"join users on users.id = follows.follower_id").Order(
+			"follows.created_at desc").Offset(page).Limit(pageSize).Scan(&list.Users).Error
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		list.NextPage = page + pageSize
+		c.JSON(http.StatusOK, list)
+	}
+}
+
+// Lists the users and public curations followed by the user in the id param. Each list has
+// its own count and is paged apart, users by the page param and curations by curation_page.
+// Curations by users a logged in caller has blocked are left out
+func GetFollowing(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c, "id")
+		if !ok {
+			return
+		}
+
+		page := pageParam(c)
+		curationPage := offsetParam(c, "curation_page")
+
+		following := db.Table("follows").Where(
+			"follows.follower_id = ? and follows.deleted_at is null", id).Session(&gorm.Session{})
+		users := following.Where("follows.followed_id <> 0").Session(&gorm.Session{})
+		curations := hideBlocked(following.Joins(
+			"join curations on curations.id = follows.curation_id").Where(
+			"follows.curation_id <> 0 and curations.private = false and curations.deleted_at is null"),
+			"curations.user_id", c.GetInt("authID")).Session(&gorm.Session{})
+
+		var list models.FollowList
+		if err := users.Count(&list.Count).Error; err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		if err := curations.Count(&list.Curation_Count).Error; err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		err := users.Select("users.id, users.username").Joins(
+			"join users on users.id = follows.followed_id").Order(
+			"follows.created_at desc").Offset(page).Limit(pageSize).Scan(&list.Users).Error
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		err = curations.Select("curations.id, curations.user_id, curations.name, curations.private").Order(
+			"follows.created_at desc").Offset(curationPage).Limit(pageSize).Scan(&list.Curations).Error
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		list.NextPage = page + pageSize
+		list.Curation_NextPage = curationPage + pageSize
+		c.JSON(http.StatusOK, list)
+	}
+}
@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
@@ -10,6 +9,8 @@ import (
 	"strconv"
 	"time"
 
+	"AT-BE/apierror"
+	"AT-BE/broker"
 	"AT-BE/models"
 	"AT-BE/utils"
 
@@ -20,82 +21,20 @@ import (
 	"gorm.io/gorm"
 )
 
-func GetEra(db *gorm.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		id := c.Param("id")
-		var era models.Era
-		db.Where("id = ?", id).Find(&era)
-
-		c.JSON(http.StatusOK, era)
-	}
-}
-
-func GetArtist(db *gorm.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		id := c.Param("id")
-		var artist models.Artist
-		db.Where("id = ?", id).Find(&artist)
-
-		c.JSON(http.StatusOK, artist)
-	}
-}
-
 func GetArtwork(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		id := c.Param("id")
-
-		var artwork models.Searches
-		if err := db.Table("searches").Where("searches.\"ID\" = ?", id).Find(&artwork).Error; err != nil {
-			c.JSON(http.StatusOK, gin.H{
-				"message":       err.Error(),
-				"request_param": c.Param("id"),
-			})
-			log.Print(err.Error())
-
+		id, ok := paramID(c, "id")
+		if !ok {
 			return
 		}
 
-		c.JSON(http.StatusOK, artwork)
-	}
-}
-
-func GetSource(db *gorm.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		id := c.Param("id")
-		var source models.Source
-		db.Where("id = ?", id).Find(&source)
-
-		c.JSON(http.StatusOK, source)
-	}
-}
-
-func GetArtworks(db *gorm.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		limit, err := strconv.Atoi(c.Query("limit"))
-		if err != nil {
-			log.Print(err)
-			c.JSON(http.StatusNotFound, gin.H{
-				"message": err.Error(),
-			})
-
+		var artwork models.Searches
+		if err := db.Table("searches").Where("searches.\"ID\" = ?", id).Take(&artwork).Error; err != nil {
+			lookupFailed(c, err, "artwork could not be found")
 			return
 		}
-		last_id := c.Query("last_id")
-
-		var artworks []models.Artwork
-		db.Where("id > ?", last_id).Limit(limit).Find(&artworks)
-
-		c.JSON(http.StatusOK, artworks)
-	}
-}
 
-func Search(db *gorm.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		term := c.Param("term")
-		var results []models.Searches
-		db.Table("searches").Where("to_tsvector(\"searches\".\"Title\" || '' || \"searches\".\"Artist_Name\") @@ plainto_tsquery(?)", term).Find(&results)
-
-		c.JSON(http.StatusOK, results)
+		c.JSON(http.StatusOK, artwork)
 	}
 }
 
@@ -104,10 +43,7 @@ func RegisterUser(db *gorm.DB) gin.HandlerFunc {
 
 		reqData, err := utils.ParseFormData(c.Request.Body)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"message": err.Error(),
-			})
-			log.Print("[Error] Unable to parse form data")
+			apierror.RespondCode(c, http.StatusBadRequest, apierror.InvalidParam, err.Error(), nil)
 
 			return
 		}
@@ -115,10 +51,7 @@ func RegisterUser(db *gorm.DB) gin.HandlerFunc {
 		pwd := reqData.Password
 		password, pwdErr := bcrypt.GenerateFromPassword([]byte(pwd), 14)
 		if pwdErr != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"message": err.Error(),
-			})
-			log.Print("Password could not be encrypted")
+			apierror.InternalError(c, pwdErr)
 
 			return
 		}
@@ -129,11 +62,14 @@ func RegisterUser(db *gorm.DB) gin.HandlerFunc {
 			Password: password,
 		}
 
-		if pwdErr == nil && err == nil {
-			db.Create(&user)
-			c.JSON(http.StatusCreated, user)
+		if err := db.Create(&user).Error; err != nil {
+			apierror.InternalError(c, err)
+
+			return
 		}
 
+		c.JSON(http.StatusCreated, user)
+
 	}
 }
 
@@ -142,10 +78,8 @@ func LoginUser(db *gorm.DB) gin.HandlerFunc {
 
 		reqData, parseErr := utils.ParseFormData(c.Request.Body)
 		if parseErr != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"message": parseErr.Error(),
-			})
-			log.Print("[Error] Unable to parse form data")
+			apierror.Respond(c, http.StatusBadRequest, errors.Wrap(parseErr, "unable to read request.body").Error(), nil)
+			log.Print(parseErr)
 
 			return
 		}
@@ -155,9 +89,7 @@ func LoginUser(db *gorm.DB) gin.HandlerFunc {
 		db.Find(&user, "username = ?", un)
 		if user.Username == "" {
 			c.Writer.Header().Add("error", "User could not be found through username")
-			c.JSON(http.StatusNotFound, gin.H{
-				"message": "user could not be found through username",
-			})
+			apierror.Respond(c, http.StatusNotFound, "user could not be found through username", nil)
 			log.Print("user could not be found through username")
 
 			return
@@ -166,9 +98,7 @@ func LoginUser(db *gorm.DB) gin.HandlerFunc {
 		pwdErr := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(pwd))
 		if pwdErr != nil {
 			c.Writer.Header().Add("error", "User could not be found through password")
-			c.JSON(http.StatusBadRequest, gin.H{
-				"password error": pwdErr.Error(),
-			})
+			apierror.Respond(c, http.StatusBadRequest, "user could not be found through password", nil)
 			log.Print(pwdErr)
 
 			return
@@ -183,10 +113,7 @@ func LoginUser(db *gorm.DB) gin.HandlerFunc {
 
 		token, err := claim.SignedString([]byte(os.Getenv("secretkey")))
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"message": err,
-			})
-			log.Print(err)
+			apierror.InternalError(c, err)
 
 			return
 		}
@@ -196,9 +123,7 @@ func LoginUser(db *gorm.DB) gin.HandlerFunc {
 		if user.ID != 0 && pwdErr == nil {
 			c.JSON(http.StatusOK, user)
 		} else {
-			c.JSON(http.StatusNotFound, gin.H{
-				"message": "unable to login",
-			})
+			apierror.Respond(c, http.StatusNotFound, "unable to login", nil)
 
 			return
 		}
@@ -215,7 +140,7 @@ func AuthenticateUser(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		cookie, err := c.Cookie("jwt")
 		if err != nil {
-			c.JSON(http.StatusNotFound, "cookie could not be found for user")
+			apierror.Respond(c, http.StatusNotFound, "cookie could not be found for user", nil)
 			log.Print("cookie could not be found for user")
 
 			return
@@ -223,9 +148,7 @@ func AuthenticateUser(db *gorm.DB) gin.HandlerFunc {
 
 		token, err := jwt.ParseWithClaims(cookie, &jwt.StandardClaims{}, keyFunc)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"message": fmt.Errorf("unauthenticated user: %+v", err),
-			})
+			apierror.Respond(c, http.StatusUnauthorized, errors.Wrap(err, "unauthenticated user").Error(), nil)
 			log.Printf("unauthenticated user: %+v", err)
 
 			return
@@ -289,9 +212,7 @@ func CheckArtworkLikes(db *gorm.DB) gin.HandlerFunc {
 
 		err := reqData.ProcessReq(c.Request)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"message": err.Error(),
-			})
+			apierror.Respond(c, http.StatusBadRequest, errors.Wrap(err, "unable to read request.body").Error(), nil)
 			log.Print(err)
 
 			return
@@ -299,11 +220,8 @@ func CheckArtworkLikes(db *gorm.DB) gin.HandlerFunc {
 
 		iID, aErr := strconv.Atoi(reqData.ItemID)
 		if aErr != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"message":         aErr.Error(),
-				"reData":          reqData.ToString(),
-				"ArtworkID":       reqData.ItemID,
-				"post-conversion": iID,
+			apierror.RespondCode(c, http.StatusBadRequest, apierror.InvalidParam, "itemID must be an artwork ID", gin.H{
+				"reqData": reqData.ToString(),
 			})
 			log.Print(aErr)
 
@@ -313,10 +231,7 @@ func CheckArtworkLikes(db *gorm.DB) gin.HandlerFunc {
 		var artworkLike models.ArtworkLikes
 		exists, err := likeExists(db, &artworkLike, reqData.UserID, iID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"message": err.Error(),
-			})
-			log.Print(err)
+			apierror.InternalError(c, err)
 
 			return
 		}
@@ -346,21 +261,16 @@ func ArtworkLike(db *gorm.DB) gin.HandlerFunc {
 		var reqData models.LikeReqData
 		err := reqData.ProcessReq(c.Request)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"errorMessage": err.Error(),
-			})
-			log.Print(err.Error())
+			apierror.Respond(c, http.StatusBadRequest, errors.Wrap(err, "unable to read request.body").Error(), nil)
+			log.Print(err)
 
 			return
 		}
 
 		iID, aErr := strconv.Atoi(reqData.ItemID)
 		if aErr != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"errorMessage":    aErr.Error(),
-				"reData":          reqData.ToString(),
-				"ArtworkID":       reqData.ItemID,
-				"post-conversion": iID,
+			apierror.RespondCode(c, http.StatusBadRequest, apierror.InvalidParam, "itemID must be an artwork ID", gin.H{
+				"reqData": reqData.ToString(),
 			})
 			log.Print(aErr)
 
@@ -370,18 +280,24 @@ func ArtworkLike(db *gorm.DB) gin.HandlerFunc {
 		var artworkLike models.ArtworkLikes
 		exists, err := likeExists(db, &artworkLike, reqData.UserID, iID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"errorMessage": err.Error(),
-			})
-			log.Print(err)
+			apierror.InternalError(c, err)
 
 			return
 		}
 
 		if exists {
+			newlyLiked := reqData.LikeStatus && !artworkLike.Like
 			artworkLike.Like = reqData.LikeStatus
 			db.Save(&artworkLike)
 
+			if newlyLiked {
+				recordActivity(db, models.Activity{
+					Actor_ID:   reqData.UserID,
+					Verb:       models.VerbArtworkLiked,
+					Artwork_ID: iID,
+				})
+			}
+
 			c.JSON(http.StatusOK, gin.H{
 				"message": "successfully updated",
 				"like":    artworkLike,
@@ -395,40 +311,44 @@ func ArtworkLike(db *gorm.DB) gin.HandlerFunc {
 
 			result := db.Create(&newArtworkLike)
 			if result.Error != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"errorMessage": result.Error,
-				})
-				log.Print(result.Error)
+				apierror.InternalError(c, result.Error)
 
 				return
 
 			} else {
+				if newArtworkLike.Like {
+					recordActivity(db, models.Activity{
+						Actor_ID:   reqData.UserID,
+						Verb:       models.VerbArtworkLiked,
+						Artwork_ID: iID,
+					})
+				}
+
 				c.JSON(http.StatusCreated, newArtworkLike)
 			}
 		}
 	}
 }
 
+// Returns the profile for the ID in the request body.
+//
+// Deprecated: looks a user up by the raw ID in the body and exposes private fields; use GET users/:username (GetProfile) instead.
 func Users(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		c.Writer.Header().Set("Deprecation", "true")
+		c.Writer.Header().Set("Link", "</users/{username}>; rel=\"successor-version\"")
 
 		id, err := ioutil.ReadAll(c.Request.Body)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"message": errors.Wrap(err, "unable to read request.body").Error(),
-			})
-			log.Print(err)
+			apierror.InternalError(c, errors.Wrap(err, "unable to read request.body"))
 
 			return
 		}
 
 		var ID string
 		if err := json.Unmarshal(id, &ID); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"message":  errors.Wrap(err, "unable to unmarshal data").Error(),
-				"data":     id,
-				"ID":       ID,
-				"req body": fmt.Sprintf("%v", c.Request.Body),
+			apierror.Respond(c, http.StatusBadRequest, errors.Wrap(err, "unable to unmarshal data").Error(), gin.H{
+				"data": string(id),
 			})
 			log.Print(err)
 
@@ -436,8 +356,17 @@ func Users(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		var user models.Users
-		db.Where("id = ?", ID).Find(&user)
-		c.JSON(http.StatusOK, user)
+		if err := db.Where("id = ?", ID).Take(&user).Error; err != nil {
+			lookupFailed(c, err, "user could not be found")
+			return
+		}
+
+		profile, ok := loadProfile(c, db, user, 0)
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, profile)
 	}
 }
 
@@ -446,9 +375,7 @@ func LikedArtworkHandler(db *gorm.DB) gin.HandlerFunc {
 		pageInt, exist := c.Get("pageInt")
 		if !exist {
 			msg, _ := c.Get("pageError")
-			c.JSON(http.StatusBadRequest, gin.H{
-				"message": msg.(error).Error(),
-			})
+			apierror.Respond(c, http.StatusBadRequest, msg.(error).Error(), nil)
 			log.Print(msg)
 
 			return
@@ -457,9 +384,7 @@ func LikedArtworkHandler(db *gorm.DB) gin.HandlerFunc {
 		userID, exist := c.Get("userID")
 		if !exist {
 			msg, _ := c.Get("userError")
-			c.JSON(http.StatusBadRequest, gin.H{
-				"message": msg.(error).Error(),
-			})
+			apierror.Respond(c, http.StatusBadRequest, msg.(error).Error(), nil)
 			log.Print(msg)
 
 			return
@@ -500,9 +425,7 @@ func NewCurationHandler(db *gorm.DB) gin.HandlerFunc {
 		var CurReq models.NewCurationReq
 		err := CurReq.ProcessReq(c.Request)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"message": errors.Wrap(err, "unable to read request.body").Error(),
-			})
+			apierror.Respond(c, http.StatusBadRequest, errors.Wrap(err, "unable to read request.body").Error(), nil)
 			log.Print(err)
 
 			return
@@ -515,9 +438,7 @@ func NewCurationHandler(db *gorm.DB) gin.HandlerFunc {
 
 		exists, err := curationAW.AlreadyExists(db)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"message": errors.Wrap(err, "curationAW already exists").Error(),
-			})
+			apierror.Respond(c, http.StatusBadRequest, errors.Wrap(err, "curationAW already exists").Error(), nil)
 			log.Print(err)
 
 			return
@@ -526,10 +447,7 @@ func NewCurationHandler(db *gorm.DB) gin.HandlerFunc {
 		if !exists {
 			result := db.Create(&curationAW)
 			if result.Error != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"errorMessage": result.Error,
-				})
-				log.Print(result.Error)
+				apierror.InternalError(c, result.Error)
 
 				return
 			}
@@ -538,21 +456,28 @@ func NewCurationHandler(db *gorm.DB) gin.HandlerFunc {
 		newCuration := models.Curations{
 			User_ID: CurReq.UserID,
 			Name:    CurReq.Name,
-			Artworks: []uint{
+			Artworks: models.IDArray{
 				curationAW.ID,
 			},
+			Private: CurReq.Private,
 		}
 
 		result := db.Create(&newCuration)
 		if result.Error != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"errorMessage": result.Error,
-			})
-			log.Print(result.Error)
+			apierror.InternalError(c, result.Error)
 
 			return
 
 		} else {
+			recordActivity(db, models.Activity{
+				Actor_ID:    CurReq.UserID,
+				Verb:        models.VerbCurationCreated,
+				Artwork_ID:  CurReq.ArtworkID,
+				Curation_ID: newCuration.ID,
+				Owner_ID:    CurReq.UserID,
+			})
+			notifyFollowers(db, newCuration)
+
 			c.JSON(http.StatusCreated, gin.H{
 				"message": "success",
 				"ID":      newCuration.ID,
@@ -562,24 +487,112 @@ func NewCurationHandler(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
+// Appends the artwork in the body to the logged in user's curation in the id param and
+// records the addition for the feed of everyone who liked the curation
+func AddCurationArtwork(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authID := c.GetInt("authID")
+		id, ok := paramID(c, "id")
+		if !ok {
+			return
+		}
+
+		var reqData models.AddCurationArtworkReq
+		if err := reqData.ProcessReq(c.Request); err != nil {
+			apierror.Respond(c, http.StatusBadRequest, errors.Wrap(err, "unable to read request.body").Error(), nil)
+			log.Print(err)
+
+			return
+		}
+
+		var cur models.Curations
+		if err := db.Where("id = ?", id).Take(&cur).Error; err != nil {
+			lookupFailed(c, err, "curation could not be found")
+			return
+		}
+
+		if cur.User_ID != authID {
+			apierror.Respond(c, http.StatusForbidden, "only the curation's owner can add artworks", nil)
+
+			return
+		}
+
+		exists, err := recordExists(db, &models.Artwork{}, reqData.ArtworkID)
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		if !exists {
+			apierror.Respond(c, http.StatusNotFound, "artwork could not be found", gin.H{
+				"artworkID": reqData.ArtworkID,
+			})
+
+			return
+		}
+
+		var added int64
+		err = db.Model(&models.CurationArtwork{}).Where(
+			"id in ? and artwork_id = ?", []uint(cur.Artworks), reqData.ArtworkID).Count(&added).Error
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		if added > 0 {
+			apierror.Respond(c, http.StatusConflict, "artwork is already in the curation", gin.H{
+				"artworkID": reqData.ArtworkID,
+			})
+
+			return
+		}
+
+		item := models.CurationArtwork{Artwork_ID: reqData.ArtworkID, Order: len(cur.Artworks) + 1}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where(&item, "artwork_id", "order").FirstOrCreate(&item).Error; err != nil {
+				return err
+			}
+
+			cur.Artworks = append(cur.Artworks, item.ID)
+
+			return tx.Model(&cur).Update("artworks", cur.Artworks).Error
+		})
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		recordActivity(db, models.Activity{
+			Actor_ID:    authID,
+			Verb:        models.VerbCurationArtworkAdded,
+			Artwork_ID:  reqData.ArtworkID,
+			Curation_ID: cur.ID,
+			Owner_ID:    cur.User_ID,
+		})
+
+		publish(broker.CurationTopic(id), "curation_artwork_added", gin.H{"id": id, "artwork_id": reqData.ArtworkID})
+
+		c.JSON(http.StatusCreated, cur)
+	}
+}
+
 func DeleteCurationHandler(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var ID int
 
 		data, err := ioutil.ReadAll(c.Request.Body)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"message": errors.Wrap(err, "unable to read request.body").Error(),
-			})
+			apierror.Respond(c, http.StatusBadRequest, errors.Wrap(err, "unable to read request.body").Error(), nil)
 			log.Print(err)
 
 			return
 		}
 
 		if err := json.Unmarshal(data, &ID); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"message": errors.Wrap(err, "unable to read request.body").Error(),
-			})
+			apierror.Respond(c, http.StatusBadRequest, errors.Wrap(err, "unable to read request.body").Error(), nil)
 			log.Print(err)
 
 			return
@@ -589,6 +602,8 @@ func DeleteCurationHandler(db *gorm.DB) gin.HandlerFunc {
 		db.Find(&cur, "ID = ?", ID)
 		db.Unscoped().Delete(&cur)
 
+		publish(broker.CurationTopic(ID), "curation_deleted", gin.H{"id": ID})
+
 		c.JSON(http.StatusAccepted, gin.H{
 			"message": "curation deleted",
 		})
@@ -600,9 +615,7 @@ func UpdateCurationNameHandler(db *gorm.DB) gin.HandlerFunc {
 		var u models.UpdateCurName
 		err := u.ProcessReq(c.Request)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"message": errors.Wrap(err, "unable to read request.body").Error(),
-			})
+			apierror.Respond(c, http.StatusBadRequest, errors.Wrap(err, "unable to read request.body").Error(), nil)
 			log.Print(err)
 
 			return
@@ -614,6 +627,8 @@ func UpdateCurationNameHandler(db *gorm.DB) gin.HandlerFunc {
 		cur.Name = u.Name
 		db.Save(&cur)
 
+		publish(broker.CurationTopic(u.ID), "curation_renamed", gin.H{"id": u.ID, "name": u.Name})
+
 		c.JSON(http.StatusAccepted, gin.H{
 			"message":  "curation name updated",
 			"new name": u.Name,
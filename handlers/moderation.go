@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"AT-BE/apierror"
+	"AT-BE/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// Checks whether blockerID has blocked blockedID
+func isBlocked(db *gorm.DB, blockerID int, blockedID int) (bool, error) {
+	var count int64
+	err := db.Model(&models.Blocks{}).Where("blocker_id = ? and blocked_id = ?", blockerID, blockedID).Count(&count).Error
+
+	return count > 0, err
+}
+
+// Leaves out the rows whose column holds a user the caller has blocked. Anonymous callers,
+// with an authID of 0, see everything
+func hideBlocked(query *gorm.DB, column string, authID int) *gorm.DB {
+	if authID == 0 {
+		return query
+	}
+
+	return query.Where(
+		column+" not in (select b.blocked_id from blocks as b where b.blocker_id = ? and b.deleted_at is null)", authID)
+}
+
+// Blocks the user in the id param for the logged in user. Any follows between the two
+// users are removed
+func BlockUser(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authID := c.GetInt("authID")
+		id, ok := paramID(c, "id")
+		if !ok {
+			return
+		}
+
+		if id == authID {
+			apierror.Respond(c, http.StatusBadRequest, "users cannot block themselves", nil)
+
+			return
+		}
+
+		exists, err := recordExists(db, &models.Users{}, id)
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		if !exists {
+			apierror.Respond(c, http.StatusNotFound, "user could not be found", nil)
+
+			return
+		}
+
+		block := models.Blocks{Blocker_ID: authID, Blocked_ID: id}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where(&block, "blocker_id", "blocked_id").FirstOrCreate(&block).Error; err != nil {
+				return err
+			}
+
+			return tx.Unscoped().Where(
+				"(follower_id = ? and followed_id = ?) or (follower_id = ? and followed_id = ?)",
+				authID, id, id, authID).Delete(&models.Follows{}).Error
+		})
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusCreated, block)
+	}
+}
+
+func UnblockUser(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authID := c.GetInt("authID")
+		id, ok := paramID(c, "id")
+		if !ok {
+			return
+		}
+
+		result := db.Unscoped().Where("blocker_id = ? and blocked_id = ?", authID, id).Delete(&models.Blocks{})
+		if result.Error != nil {
+			apierror.InternalError(c, result.Error)
+
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"message": "user unblocked",
+		})
+	}
+}
+
+// Lists the users the logged in user has blocked
+func GetBlocks(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authID := c.GetInt("authID")
+
+		var blocked []models.PublicUser
+		err := db.Table("blocks").Select("users.id, users.username").Joins(
+			"join users on users.id = blocks.blocked_id").Where(
+			"blocks.blocker_id = ? and blocks.deleted_at is null", authID).Order(
+			"users.username").Scan(&blocked).Error
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, blocked)
+	}
+}
+
+// Reports a user or a curation to the moderation queue
+func ReportHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authID := c.GetInt("authID")
+
+		var r models.NewReportReq
+		if err := r.ProcessReq(c.Request); err != nil {
+			apierror.Respond(c, http.StatusBadRequest, errors.Wrap(err, "unable to read request.body").Error(), nil)
+			log.Print(err)
+
+			return
+		}
+
+		r.Reason = strings.TrimSpace(r.Reason)
+		switch {
+		case r.Reason == "":
+			apierror.Respond(c, http.StatusBadRequest, "a reason is required", nil)
+
+			return
+		case len(r.Reason) > models.MaxReportReasonLength:
+			apierror.Respond(c, http.StatusBadRequest, "reason is too long", gin.H{
+				"max": models.MaxReportReasonLength,
+			})
+
+			return
+		case (r.UserID == 0) == (r.CurationID == 0):
+			apierror.Respond(c, http.StatusBadRequest, "exactly one of userID and curationID is required", gin.H{
+				"reqData": r.ToString(),
+			})
+
+			return
+		}
+
+		report := models.Reports{
+			Reporter_ID: authID,
+			User_ID:     r.UserID,
+			Reason:      r.Reason,
+			Status:      models.ReportOpen,
+		}
+
+		if r.CurationID != 0 {
+			cur, err := visibleCuration(db, r.CurationID, authID)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					apierror.Respond(c, http.StatusNotFound, "curation could not be found", nil)
+				} else {
+					apierror.InternalError(c, err)
+				}
+
+				return
+			}
+
+			report.Curation_ID = cur.ID
+			report.User_ID = cur.User_ID
+		} else {
+			exists, err := recordExists(db, &models.Users{}, r.UserID)
+			if err != nil {
+				apierror.InternalError(c, err)
+
+				return
+			}
+
+			if !exists {
+				apierror.Respond(c, http.StatusNotFound, "user could not be found", nil)
+
+				return
+			}
+		}
+
+		if err := db.Create(&report).Error; err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusCreated, report)
+	}
+}
+
+// Lists reports for admins, oldest first. The status param defaults to open
+func GetReports(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status := c.DefaultQuery("status", models.ReportOpen)
+		if status != models.ReportOpen && status != models.ReportResolved && status != models.ReportDismissed {
+			apierror.RespondCode(c, http.StatusBadRequest, apierror.InvalidParam, "status must be open, resolved or dismissed", gin.H{
+				"request_param": status,
+			})
+
+			return
+		}
+
+		page := pageParam(c)
+
+		var reports []models.Reports
+		err := db.Where("status = ?", status).Order("id").Offset(page).Limit(pageSize).Find(&reports).Error
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"reports": reports,
+			"page":    page + pageSize,
+		})
+	}
+}
+
+// Closes an open report with the given status, used for both resolve and dismiss
+func DecideReport(db *gorm.DB, status string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authID := c.GetInt("authID")
+		id, ok := paramID(c, "id")
+		if !ok {
+			return
+		}
+
+		var d models.ReportDecision
+		if err := d.ProcessReq(c.Request); err != nil {
+			apierror.Respond(c, http.StatusBadRequest, errors.Wrap(err, "unable to read request.body").Error(), nil)
+			log.Print(err)
+
+			return
+		}
+
+		var report models.Reports
+		if err := db.Take(&report, id).Error; err != nil {
+			lookupFailed(c, err, "report could not be found")
+			return
+		}
+
+		if report.Status != models.ReportOpen {
+			apierror.Respond(c, http.StatusConflict, "report has already been "+report.Status, nil)
+
+			return
+		}
+
+		report.Status = status
+		report.Resolved_By = authID
+		report.Note = d.Note
+		if err := db.Save(&report).Error; err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusAccepted, report)
+	}
+}
@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"AT-BE/apierror"
+	"AT-BE/broker"
+	"AT-BE/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// number of notifications returned per page
+const notificationsSize = 20
+
+// Checks whether the user has muted the notification category
+func categoryMuted(db *gorm.DB, userID int, category string) (bool, error) {
+	var count int64
+	err := db.Model(&models.NotificationPrefs{}).Where(
+		"user_id = ? and category = ? and muted = true", userID, category).Count(&count).Error
+
+	return count > 0, err
+}
+
+// Sends a notification unless the recipient has muted its category, is the actor, or either
+// of them has blocked the other. Failures are logged rather than returned so that the
+// triggering request still succeeds
+func notify(db *gorm.DB, n models.Notifications) {
+	if n.User_ID == n.Actor_ID {
+		return
+	}
+
+	var blocks int64
+	err := db.Model(&models.Blocks{}).Where(
+		"(blocker_id = ? and blocked_id = ?) or (blocker_id = ? and blocked_id = ?)",
+		n.User_ID, n.Actor_ID, n.Actor_ID, n.User_ID).Count(&blocks).Error
+	if err != nil {
+		log.Print(errors.Wrap(err, "unable to read blocks"))
+		return
+	}
+
+	if blocks > 0 {
+		return
+	}
+
+	muted, err := categoryMuted(db, n.User_ID, n.Category)
+	if err != nil {
+		log.Print(errors.Wrapf(err, "unable to read %v preference", n.Category))
+		return
+	}
+
+	if muted {
+		return
+	}
+
+	if err := db.Create(&n).Error; err != nil {
+		log.Print(errors.Wrapf(err, "unable to send %v notification", n.Category))
+		return
+	}
+
+	publish(broker.UserTopic(n.User_ID), n.Category, n)
+}
+
+// Notifies everyone following the curation's owner that they published a new curation
+func notifyFollowers(db *gorm.DB, cur models.Curations) {
+	if cur.Private {
+		return
+	}
+
+	// blocked pairs are skipped by notify
+	var followerIDs []int
+	err := db.Model(&models.Follows{}).Where("followed_id = ?", cur.User_ID).Pluck("follower_id", &followerIDs).Error
+	if err != nil {
+		log.Print(errors.Wrap(err, "unable to read followers"))
+		return
+	}
+
+	for _, id := range followerIDs {
+		notify(db, models.Notifications{
+			User_ID:     id,
+			Actor_ID:    cur.User_ID,
+			Category:    models.NotifyNewCuration,
+			Curation_ID: cur.ID,
+		})
+	}
+}
+
+// Lists the caller's notifications, newest first. unread=true skips notifications already
+// read, and next_cursor from the previous response can be passed as the cursor param
+func GetNotifications(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authID := c.GetInt("authID")
+
+		query := db.Where("user_id = ?", authID)
+		if c.Query("unread") == "true" {
+			query = query.Where("read_at is null")
+		}
+
+		if cursor := c.Query("cursor"); cursor != "" {
+			id, err := strconv.Atoi(cursor)
+			if err != nil {
+				apierror.RespondCode(c, http.StatusBadRequest, apierror.InvalidParam, "cursor must be a notification ID", gin.H{
+					"request_param": cursor,
+				})
+
+				return
+			}
+
+			query = query.Where("id < ?", id)
+		}
+
+		var list models.NotificationList
+		if err := query.Order("id desc").Limit(notificationsSize).Find(&list.Notifications).Error; err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		err := db.Model(&models.Notifications{}).Where("user_id = ? and read_at is null", authID).Count(&list.Unread).Error
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		if len(list.Notifications) == notificationsSize {
+			list.NextCursor = list.Notifications[len(list.Notifications)-1].ID
+		}
+
+		c.JSON(http.StatusOK, list)
+	}
+}
+
+func UnreadNotificationCount(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authID := c.GetInt("authID")
+
+		var count int64
+		err := db.Model(&models.Notifications{}).Where("user_id = ? and read_at is null", authID).Count(&count).Error
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"unread": count,
+		})
+	}
+}
+
+func MarkNotificationRead(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authID := c.GetInt("authID")
+		id, ok := paramID(c, "id")
+		if !ok {
+			return
+		}
+
+		result := db.Model(&models.Notifications{}).Where(
+			"id = ? and user_id = ? and read_at is null", id, authID).Update("read_at", time.Now())
+		if result.Error != nil {
+			apierror.InternalError(c, result.Error)
+
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"message": "notification read",
+			"updated": result.RowsAffected,
+		})
+	}
+}
+
+func MarkAllNotificationsRead(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authID := c.GetInt("authID")
+
+		result := db.Model(&models.Notifications{}).Where(
+			"user_id = ? and read_at is null", authID).Update("read_at", time.Now())
+		if result.Error != nil {
+			apierror.InternalError(c, result.Error)
+
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"message": "all notifications read",
+			"updated": result.RowsAffected,
+		})
+	}
+}
+
+// Returns every notification category and whether the caller has muted it
+func GetNotificationPrefs(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authID := c.GetInt("authID")
+
+		var prefs []models.NotificationPrefs
+		if err := db.Where("user_id = ?", authID).Find(&prefs).Error; err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		muted := make(map[string]bool)
+		for _, category := range models.NotificationCategories {
+			muted[category] = false
+		}
+
+		// prefs saved for categories no longer listed are left out
+		for _, p := range prefs {
+			if _, ok := muted[p.Category]; ok {
+				muted[p.Category] = p.Muted
+			}
+		}
+
+		c.JSON(http.StatusOK, muted)
+	}
+}
+
+func UpdateNotificationPref(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authID := c.GetInt("authID")
+
+		var u models.UpdateNotificationPref
+		if err := u.ProcessReq(c.Request); err != nil {
+			apierror.Respond(c, http.StatusBadRequest, errors.Wrap(err, "unable to read request.body").Error(), nil)
+			log.Print(err)
+
+			return
+		}
+
+		known := false
+		for _, category := range models.NotificationCategories {
+			known = known || category == u.Category
+		}
+
+		if !known {
+			apierror.Respond(c, http.StatusBadRequest, "unknown notification category", gin.H{
+				"reqData": u.ToString(),
+			})
+
+			return
+		}
+
+		pref := models.NotificationPrefs{User_ID: authID, Category: u.Category}
+		if err := db.Where(&pref, "user_id", "category").FirstOrInit(&pref).Error; err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		pref.Muted = u.Muted
+		if err := db.Save(&pref).Error; err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusAccepted, pref)
+	}
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"AT-BE/apierror"
+	"AT-BE/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// Builds the public view of user, leaving out what their privacy settings hide. Their
+// curations are left out when the caller in authID has blocked them. Returns false when an
+// error response has been sent
+func loadProfile(c *gin.Context, db *gorm.DB, user models.Users, authID int) (models.Profile, bool) {
+	profile := models.Profile{
+		ID:       user.ID,
+		Username: user.Username,
+		Joined:   user.CreatedAt,
+		Private:  user.Private_Profile,
+	}
+
+	if user.Private_Profile {
+		return profile, true
+	}
+
+	profile.Bio = user.Bio
+
+	if user.Avatar_Artwork_ID != 0 {
+		var avatar models.Searches
+		result := db.Table("searches").Where("searches.\"ID\" = ?", user.Avatar_Artwork_ID).Limit(1).Find(&avatar)
+		if result.Error != nil {
+			log.Print(result.Error)
+		} else if result.RowsAffected > 0 {
+			profile.Avatar = &avatar
+		}
+	}
+
+	query := db.Model(&models.Curations{}).Where("user_id = ? and private = false", user.ID)
+	err := hideBlocked(query, "user_id", authID).Order("created_at desc").Find(&profile.Curations).Error
+	if err != nil {
+		apierror.InternalError(c, err)
+
+		return profile, false
+	}
+
+	if user.Show_Likes {
+		var likes int64
+		err := db.Model(&models.ArtworkLikes{}).Where("user_id = ? and \"like\" = true", user.ID).Count(&likes).Error
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return profile, false
+		}
+
+		profile.Liked_Count = &likes
+	}
+
+	err = db.Model(&models.Follows{}).Where("followed_id = ?", user.ID).Count(&profile.Followers).Error
+	if err == nil {
+		err = db.Model(&models.Follows{}).Where("follower_id = ? and followed_id <> 0", user.ID).Count(&profile.Following).Error
+	}
+	if err != nil {
+		apierror.InternalError(c, err)
+
+		return profile, false
+	}
+
+	return profile, true
+}
+
+// Returns the public profile of the user in the username param, see loadProfile. The route shares the :id
+// wildcard with the follow routes, so the username is read from c.Param("id")
+func GetProfile(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username := c.Param("id")
+
+		var user models.Users
+		if err := db.Where("username = ?", username).Take(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				apierror.Respond(c, http.StatusNotFound, "user could not be found through username", gin.H{
+					"request_param": username,
+				})
+			} else {
+				apierror.InternalError(c, err)
+			}
+
+			return
+		}
+
+		profile, ok := loadProfile(c, db, user, c.GetInt("authID"))
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, profile)
+	}
+}
+
+// Updates the logged in user's profile fields and privacy toggles
+func UpdateProfileHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authID := c.GetInt("authID")
+
+		var u models.UpdateProfile
+		if err := u.ProcessReq(c.Request); err != nil {
+			apierror.Respond(c, http.StatusBadRequest, errors.Wrap(err, "unable to read request.body").Error(), nil)
+			log.Print(err)
+
+			return
+		}
+
+		updates := make(map[string]interface{})
+
+		if u.Bio != nil {
+			if len(*u.Bio) > models.MaxBioLength {
+				apierror.Respond(c, http.StatusBadRequest, "bio is too long", gin.H{
+					"max": models.MaxBioLength,
+				})
+
+				return
+			}
+			updates["bio"] = *u.Bio
+		}
+
+		if u.Avatar_Artwork_ID != nil {
+			if *u.Avatar_Artwork_ID != 0 {
+				var count int64
+				err := db.Table("searches").Where("searches.\"ID\" = ?", *u.Avatar_Artwork_ID).Count(&count).Error
+				if err != nil {
+					apierror.InternalError(c, err)
+
+					return
+				}
+
+				if count == 0 {
+					apierror.Respond(c, http.StatusBadRequest, "avatar artwork could not be found", gin.H{
+						"reqData": u.ToString(),
+					})
+
+					return
+				}
+			}
+			updates["avatar_artwork_id"] = *u.Avatar_Artwork_ID
+		}
+
+		if u.Show_Likes != nil {
+			updates["show_likes"] = *u.Show_Likes
+		}
+
+		if u.Private_Profile != nil {
+			updates["private_profile"] = *u.Private_Profile
+		}
+
+		var user models.Users
+		if err := db.Take(&user, authID).Error; err != nil {
+			apierror.Respond(c, http.StatusNotFound, "user could not be found", nil)
+			log.Print(err)
+
+			return
+		}
+
+		if len(updates) > 0 {
+			if err := db.Model(&user).Updates(updates).Error; err != nil {
+				apierror.InternalError(c, err)
+
+				return
+			}
+		}
+
+		c.JSON(http.StatusAccepted, user)
+	}
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"math/rand"
+	"net/http"
+	"os"
+	"time"
+
+	"AT-BE/apierror"
+	"AT-BE/models"
+	"AT-BE/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// works without an image are never picked at random or as the artwork of the day
+const hasImage = "coalesce(a.image, '') <> '' and coalesce(a.image_small, '') <> ''"
+
+// Responds with the search row of the work at offset among works, a query over
+// artwork_migrate_artwork aliased as a ordered by ID
+func artworkAt(c *gin.Context, db *gorm.DB, works *gorm.DB, offset int) (models.Searches, bool) {
+	var artwork models.Searches
+
+	var id int
+	err := works.Session(&gorm.Session{}).Select("a.id").Order("a.id").Offset(offset).Limit(1).Scan(&id).Error
+	if err != nil {
+		apierror.InternalError(c, err)
+		return artwork, false
+	}
+
+	if err := db.Table("searches").Where("searches.\"ID\" = ?", id).Take(&artwork).Error; err != nil {
+		lookupFailed(c, err, "artwork could not be found")
+		return artwork, false
+	}
+
+	return artwork, true
+}
+
+// Returns a random artwork with an image, optionally narrowed by the same filters as
+// GetArtworks such as source_id and era
+func GetRandomArtwork(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		works, ok := filterArtworks(c, db.Table("artwork_migrate_artwork as a").Where(hasImage))
+		if !ok {
+			return
+		}
+
+		var count int64
+		if err := works.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+			apierror.InternalError(c, err)
+			return
+		}
+		if count == 0 {
+			apierror.Respond(c, http.StatusNotFound, "no artwork matches the filters", nil)
+			return
+		}
+
+		artwork, ok := artworkAt(c, db, works, rand.Intn(int(count)))
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, artwork)
+	}
+}
+
+// Returns the artwork of the day for the calendar day in the timezone env variable. The pick
+// is derived from the date alone, so every instance returns the same work for a day, and only
+// changes within a day when works with images are added or removed
+func GetDailyArtwork(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		works := db.Table("artwork_migrate_artwork as a").Where(hasImage)
+
+		var count int64
+		if err := works.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+			apierror.InternalError(c, err)
+			return
+		}
+		if count == 0 {
+			apierror.Respond(c, http.StatusNotFound, "no artwork has an image", nil)
+			return
+		}
+
+		day := utils.Day(time.Now(), os.Getenv("timezone"))
+		artwork, ok := artworkAt(c, db, works, utils.DayIndex(day, int(count)))
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, models.DailyArtwork{
+			Date:    day,
+			Artwork: artwork,
+		})
+	}
+}
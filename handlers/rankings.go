@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"AT-BE/apierror"
+	"AT-BE/models"
+	"AT-BE/rankings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Reads the limit param for a ranking, capped at the number of entries the rankings job keeps.
+// Returns false when a 400 has been sent
+func rankingLimit(c *gin.Context) (int, bool) {
+	limit, set, ok := intQuery(c, "limit")
+	if !ok {
+		return 0, false
+	}
+	if !set || limit <= 0 {
+		limit = defaultBrowseLimit
+	}
+	if limit > rankings.Size {
+		limit = rankings.Size
+	}
+
+	return limit, true
+}
+
+// Reads the window param of a trending ranking, defaulting to 7d. Returns false when a 400
+// has been sent
+func trendingWindow(c *gin.Context) (string, bool) {
+	window := c.DefaultQuery("window", "7d")
+	if _, ok := rankings.Windows[window]; !ok {
+		apierror.RespondCode(c, http.StatusBadRequest, apierror.InvalidParam, "window must be one of "+strings.Join(rankings.Periods(), ", "), gin.H{
+			"request_param": window,
+		})
+
+		return "", false
+	}
+
+	return window, true
+}
+
+// Reads the top limit entries of the kind ranking over period, setting the period and
+// computed_at of list
+func loadRanking(db *gorm.DB, kind string, period string, limit int, list *models.RankingList) ([]models.Rankings, error) {
+	list.Period = period
+
+	var entries []models.Rankings
+	err := db.Where("kind = ? and period = ?", kind, period).Order("rank").Limit(limit).Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) > 0 {
+		list.Computed_At = &entries[0].Computed_At
+	}
+
+	return entries, nil
+}
+
+// Responds with the artwork ranking over period
+func artworkRanking(db *gorm.DB, c *gin.Context, period string) {
+	limit, ok := rankingLimit(c)
+	if !ok {
+		return
+	}
+
+	list := models.RankingList{Artworks: []models.RankedArtwork{}}
+	entries, err := loadRanking(db, models.RankArtworks, period, limit, &list)
+	if err != nil {
+		apierror.InternalError(c, err)
+		return
+	}
+
+	ids := make([]int, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.Item_ID
+	}
+
+	var artworks []models.Searches
+	if len(ids) > 0 {
+		if err := db.Table("searches").Where("searches.\"ID\" in ?", ids).Find(&artworks).Error; err != nil {
+			apierror.InternalError(c, err)
+			return
+		}
+	}
+
+	byID := make(map[string]models.Searches, len(artworks))
+	for _, artwork := range artworks {
+		byID[artwork.ID] = artwork
+	}
+
+	for _, entry := range entries {
+		if artwork, ok := byID[strconv.Itoa(entry.Item_ID)]; ok {
+			list.Artworks = append(list.Artworks, models.RankedArtwork{
+				Searches: artwork,
+				Rank:     entry.Rank,
+				Score:    entry.Score,
+				Likes:    entry.Likes,
+			})
+		}
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// Returns the most liked artworks of all time, up to the limit param. Rankings are recomputed
+// periodically by the rankings job, see computed_at
+func GetPopularArtworks(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		artworkRanking(db, c, rankings.AllTime)
+	}
+}
+
+// Returns the artworks trending over the window param (24h, 7d or 30d), scored by likes in
+// the window with older likes counting for less
+func GetTrendingArtworks(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		window, ok := trendingWindow(c)
+		if !ok {
+			return
+		}
+
+		artworkRanking(db, c, window)
+	}
+}
+
+// Returns the public curations trending over the window param, see GetTrendingArtworks.
+// Curations made private since the rankings were computed, and those by users a logged in
+// caller has blocked, are left out
+func GetTrendingCurations(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		window, ok := trendingWindow(c)
+		if !ok {
+			return
+		}
+
+		limit, ok := rankingLimit(c)
+		if !ok {
+			return
+		}
+
+		list := models.RankingList{Curations: []models.RankedCuration{}}
+		entries, err := loadRanking(db, models.RankCurations, window, limit, &list)
+		if err != nil {
+			apierror.InternalError(c, err)
+			return
+		}
+
+		ids := make([]int, len(entries))
+		for i, entry := range entries {
+			ids[i] = entry.Item_ID
+		}
+
+		var curations []models.CurationSummary
+		if len(ids) > 0 {
+			query := db.Model(&models.Curations{}).Where("id in ? and private = false", ids)
+			err := hideBlocked(query, "user_id", c.GetInt("authID")).Find(&curations).Error
+			if err != nil {
+				apierror.InternalError(c, err)
+				return
+			}
+		}
+
+		byID := make(map[uint]models.CurationSummary, len(curations))
+		for _, cur := range curations {
+			byID[cur.ID] = cur
+		}
+
+		for _, entry := range entries {
+			if cur, ok := byID[uint(entry.Item_ID)]; ok {
+				list.Curations = append(list.Curations, models.RankedCuration{
+					CurationSummary: cur,
+					Rank:            entry.Rank,
+					Score:           entry.Score,
+					Likes:           entry.Likes,
+				})
+			}
+		}
+
+		c.JSON(http.StatusOK, list)
+	}
+}
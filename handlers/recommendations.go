@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"AT-BE/apierror"
+	"AT-BE/models"
+	"AT-BE/recommend"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// weights of what a work shares with the caller's liked or curated works when falling back to
+// content
+const (
+	sameArtistWeight = 3
+	sameEraWeight    = 2
+	sameMediumWeight = 1
+)
+
+// scores each work by what it shares with the seed works, whose IDs are passed once for each
+// of the three placeholders
+var contentScore = fmt.Sprintf("(case when a.artist_id in (select l.artist_id from artwork_migrate_artwork as l "+
+	"where l.id in ?) then %v else 0 end) + (case when ar.era in (select lar.era from artwork_migrate_artwork as l "+
+	"join artwork_migrate_artist as lar on lar.id = l.artist_id where l.id in ?) then %v else 0 end) + "+
+	"(case when coalesce(a.medium, '') <> '' and a.medium in (select l.medium from artwork_migrate_artwork as l "+
+	"where l.id in ?) then %v else 0 end)", sameArtistWeight, sameEraWeight, sameMediumWeight)
+
+// Adds the works picked by query, which selects id and score, to recs until there are limit,
+// skipping works already recommended
+func addRecommendations(query *gorm.DB, recs *[]models.Recommendation, exclude []int, limit int, reason string) error {
+	if len(*recs) >= limit {
+		return nil
+	}
+	if len(exclude) > 0 {
+		query = query.Where("id not in ?", exclude)
+	}
+
+	var picked []recommend.Scored
+	if err := query.Limit(limit - len(*recs)).Scan(&picked).Error; err != nil {
+		return err
+	}
+
+	for _, p := range picked {
+		rec := models.Recommendation{Score: p.Score, Reason: reason}
+		rec.ID = strconv.Itoa(p.ID)
+		*recs = append(*recs, rec)
+	}
+
+	return nil
+}
+
+// artworks in the user's curations, most recently updated curations first
+const curatedArtworks = `select ca.artwork_id from curations as cur
+	join curation_artwork as ca on ca.id = any(cur.artworks) and ca.deleted_at is null
+	where cur.user_id = ? and cur.deleted_at is null
+	order by cur.updated_at desc
+	limit ?`
+
+// Recommends artworks for the caller, up to the limit param. Works liked by the same users as
+// the caller's likes come first, from the model built by the recommendations command. When
+// that runs short, works sharing an artist, era or medium with their likes follow, then the
+// most liked works. Callers who have not liked anything yet get works similar to the ones in
+// their curations instead
+func GetRecommendations(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authID := c.GetInt("authID")
+
+		limit, set, ok := intQuery(c, "limit")
+		if !ok {
+			return
+		}
+		if !set || limit <= 0 {
+			limit = defaultBrowseLimit
+		}
+		if limit > maxBrowseLimit {
+			limit = maxBrowseLimit
+		}
+
+		var liked []int
+		err := db.Model(&models.ArtworkLikes{}).Where("user_id = ? and artwork_likes.like = true", authID).Order(
+			"updated_at desc").Limit(recommend.MaxUserLikes).Pluck("artwork_id", &liked).Error
+		if err != nil {
+			apierror.InternalError(c, err)
+			return
+		}
+
+		// works the similar works stage compares against
+		seeds := liked
+		if len(liked) == 0 {
+			if err := db.Raw(curatedArtworks, authID, recommend.MaxUserLikes).Scan(&seeds).Error; err != nil {
+				apierror.InternalError(c, err)
+				return
+			}
+		}
+
+		recs := []models.Recommendation{}
+		exclude := func() []int {
+			ids := append([]int{}, seeds...)
+			for _, rec := range recs {
+				id, _ := strconv.Atoi(rec.ID)
+				ids = append(ids, id)
+			}
+			return ids
+		}
+
+		if len(liked) > 0 {
+			together := db.Table("(?) as n", db.Table("artwork_neighbours").Select(
+				"neighbour_id as id, sum(score) as score").Where("artwork_id in ?", liked).Group(
+				"neighbour_id")).Order("score desc, id")
+			if err := addRecommendations(together, &recs, exclude(), limit, models.ReasonLikedTogether); err != nil {
+				apierror.InternalError(c, err)
+				return
+			}
+		}
+
+		if len(seeds) > 0 {
+			similar := db.Table("(?) as s", db.Table("artwork_migrate_artwork as a").Select(
+				"a.id, "+contentScore+" as score, coalesce(pop.likes, 0) as likes", seeds, seeds, seeds).Joins(
+				"left join artwork_migrate_artist as ar on ar.id = a.artist_id").Joins(
+				popularityJoin)).Where("score > 0").Order("score desc, likes desc, id")
+			if err := addRecommendations(similar, &recs, exclude(), limit, models.ReasonSimilarWorks); err != nil {
+				apierror.InternalError(c, err)
+				return
+			}
+		}
+
+		popular := db.Table("(?) as p", db.Table("artwork_migrate_artwork as a").Select(
+			"a.id, coalesce(pop.likes, 0) as score").Joins(popularityJoin)).Order("score desc, id")
+		if err := addRecommendations(popular, &recs, exclude(), limit, models.ReasonPopular); err != nil {
+			apierror.InternalError(c, err)
+			return
+		}
+
+		ids := make([]string, len(recs))
+		for i, rec := range recs {
+			ids[i] = rec.ID
+		}
+
+		var artworks []models.Searches
+		if len(ids) > 0 {
+			if err := db.Table("searches").Where("searches.\"ID\" in ?", ids).Find(&artworks).Error; err != nil {
+				apierror.InternalError(c, err)
+				return
+			}
+		}
+
+		byID := make(map[string]models.Searches, len(artworks))
+		for _, artwork := range artworks {
+			byID[artwork.ID] = artwork
+		}
+
+		found := make([]models.Recommendation, 0, len(recs))
+		for _, rec := range recs {
+			if artwork, ok := byID[rec.ID]; ok {
+				rec.Searches = artwork
+				found = append(found, rec)
+			}
+		}
+
+		c.JSON(http.StatusOK, found)
+	}
+}
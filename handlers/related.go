@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"AT-BE/apierror"
+	"AT-BE/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultRelatedLimit = 8
+	maxRelatedLimit     = 20
+)
+
+// public curations containing the artwork, and how many of them each other artwork shares.
+// Curations list curation_artwork IDs, which point at the artwork
+const coCuratedJoin = `join (
+	select other.artwork_id, count(distinct cur.id) as shared
+	from curations as cur
+	join curation_artwork as this on this.id = any(cur.artworks) and this.artwork_id = ? and this.deleted_at is null
+	join curation_artwork as other on other.id = any(cur.artworks) and other.deleted_at is null
+	where cur.private = false and cur.deleted_at is null
+	group by other.artwork_id
+) as co on co.artwork_id = a.id`
+
+// Loads up to limit artworks for one group of related works, leaving out the IDs in exclude
+func relatedGroup(db *gorm.DB, exclude []int, limit int, build func(*gorm.DB) *gorm.DB) ([]models.Searches, error) {
+	query := db.Table("searches as s").Select("s.*").Joins(
+		"join artwork_migrate_artwork as a on a.id = s.\"ID\"").Where(
+		"a.id not in ?", exclude)
+
+	group := []models.Searches{}
+	err := build(query).Limit(limit).Scan(&group).Error
+
+	return group, err
+}
+
+// Lists works related to the artwork in the id param: by the same artist, that appear with it
+// in public curations, from the same era and from the same source. Groups are filled in that
+// order and an artwork already in one group is left out of the rest. The limit param caps
+// each group. Within a group, the most liked works come first
+func GetRelatedArtworks(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c, "id")
+		if !ok {
+			return
+		}
+
+		limit, set, ok := intQuery(c, "limit")
+		if !ok {
+			return
+		}
+		if !set || limit <= 0 {
+			limit = defaultRelatedLimit
+		}
+		if limit > maxRelatedLimit {
+			limit = maxRelatedLimit
+		}
+
+		var artwork struct {
+			models.Artwork
+			Era string
+		}
+		err := db.Table("artwork_migrate_artwork as a").Select("a.*, ar.era").Joins(
+			"left join artwork_migrate_artist as ar on ar.id = a.artist_id").Where(
+			"a.id = ?", id).Take(&artwork).Error
+		if err != nil {
+			lookupFailed(c, err, "artwork could not be found")
+			return
+		}
+
+		popular := func(query *gorm.DB) *gorm.DB {
+			return query.Joins(popularityJoin).Order("coalesce(pop.likes, 0) desc, a.id")
+		}
+
+		related := models.RelatedArtworks{
+			Artist:    []models.Searches{},
+			Curations: []models.Searches{},
+			Era:       []models.Searches{},
+			Source:    []models.Searches{},
+		}
+
+		groups := []struct {
+			into  *[]models.Searches
+			skip  bool
+			build func(*gorm.DB) *gorm.DB
+		}{
+			{&related.Artist, artwork.Artist_ID == 0, func(q *gorm.DB) *gorm.DB {
+				return popular(q.Where("a.artist_id = ?", artwork.Artist_ID))
+			}},
+			{&related.Curations, false, func(q *gorm.DB) *gorm.DB {
+				return q.Joins(coCuratedJoin, id).Order("co.shared desc, a.id")
+			}},
+			{&related.Era, artwork.Era == "", func(q *gorm.DB) *gorm.DB {
+				return popular(q.Joins("join artwork_migrate_artist as ar on ar.id = a.artist_id").Where("ar.era = ?", artwork.Era))
+			}},
+			{&related.Source, artwork.Source_ID == 0, func(q *gorm.DB) *gorm.DB {
+				return popular(q.Where("a.source_id = ?", artwork.Source_ID))
+			}},
+		}
+
+		exclude := []int{id}
+		for _, group := range groups {
+			if group.skip {
+				continue
+			}
+
+			found, err := relatedGroup(db, exclude, limit, group.build)
+			if err != nil {
+				apierror.InternalError(c, err)
+
+				return
+			}
+
+			*group.into = found
+			for _, work := range found {
+				if artworkID, err := strconv.Atoi(work.ID); err == nil {
+					exclude = append(exclude, artworkID)
+				}
+			}
+		}
+
+		c.JSON(http.StatusOK, related)
+	}
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"AT-BE/apierror"
+	"AT-BE/imaging"
+	"AT-BE/models"
+	"AT-BE/search"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// most values returned per facet
+	facetSize = 20
+
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+
+	// largest CIELAB difference from the colour param for a palette colour to match, unless
+	// the colour_distance param is set
+	defaultColourDistance = 20
+	// smallest share of an image a palette colour must cover to count as dominant
+	minColourWeight = 0.1
+)
+
+// options passed to ts_headline for the highlighted snippet
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=25, MinWords=8"
+
+// start year of the century in the DOR text, e.g. 1800 for "c. 1817"
+const centuryBucket = "(cast(substring(s.\"DOR\" from '[0-9]{4}') as int) / 100) * 100"
+
+// facets returned with search results. param is the query param that filters on the facet
+var searchFacets = []struct {
+	param string
+	expr  string
+}{
+	{"source", "s.\"Abb\""},
+	{"artist", "s.\"Artist_Name\""},
+	{"era", "ar.era"},
+	{"medium", "a.medium"},
+	{"century", centuryBucket},
+}
+
+// what a search request is for, read from its params
+type searchParams struct {
+	query  *search.Query
+	config string
+	// nil unless the colour param is set
+	colour   *imaging.Lab
+	distance float64
+}
+
+// Builds the search for the parsed term over searches aliased as s, joined to the artwork and
+// artist tables for the era and medium facets. Every facet filter in the request is applied except
+// the one named by skip, so that a facet's counts are not narrowed by its own selection
+func searchQuery(db *gorm.DB, c *gin.Context, p searchParams, skip string) (*gorm.DB, error) {
+	// d.document is the weighted tsvector maintained by the triggers in models.Migrate
+	query := db.Table("searches as s").Joins(
+		"join search_documents as d on d.artwork_id = s.\"ID\" and d.config = cast(? as regconfig)", p.config).Joins(
+		"left join artwork_migrate_artwork as a on a.id = s.\"ID\"").Joins(
+		"left join artwork_migrate_artist as ar on ar.id = a.artist_id")
+
+	if condition, args := p.query.Condition(p.config); condition != "" {
+		query = query.Where(condition, args...)
+	}
+
+	// pc.distance is how far the artwork's closest dominant colour is from the colour param
+	if p.colour != nil {
+		query = query.Joins(
+			"join lateral (select min(sqrt(power(ac.lab_l - ?, 2) + power(ac.lab_a - ?, 2) + power(ac.lab_b - ?, 2))) as distance "+
+				"from artwork_colours as ac where ac.artwork_id = s.\"ID\" and ac.weight >= ?) as pc on pc.distance <= ?",
+			p.colour.L, p.colour.A, p.colour.B, minColourWeight, p.distance)
+	}
+
+	for _, facet := range searchFacets {
+		value := c.Query(facet.param)
+		if value == "" || facet.param == skip {
+			continue
+		}
+
+		if facet.param == "century" {
+			century, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, err
+			}
+			query = query.Where(facet.expr+" = ?", century)
+		} else {
+			query = query.Where(facet.expr+" = ?", value)
+		}
+	}
+
+	return query, nil
+}
+
+// Searches artwork titles, artist names, medium, culture, nationality and descriptions for
+// the term param, best matches first. The term can also use the field, range and negation
+// syntax parsed by search.Parse, e.g. artist:"Monet" date:1870..1890 -water.
+//
+// Results are paginated with the page (row offset) and limit params, and total counts every
+// match. The response also includes counts for each facet in searchFacets, and the facet params
+// (e.g. ?source=RM&century=1800) narrow both the results and the other facets' counts. When
+// nothing matches, close spellings of titles and artist names are returned instead.
+//
+// Accents are ignored, and words are stemmed for the language in the lang param or the
+// Accept-Language header, see search.Language.
+//
+// The colour param (e.g. ?colour=1e3a8a) keeps artworks with a dominant palette colour within
+// colour_distance of it in CIELAB, closest first among equally relevant results. It can be used
+// without a term on the search route, which lists artworks by colour alone
+func Search(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p := searchParams{query: &search.Query{}}
+
+		if colour := c.Query("colour"); colour != "" {
+			rgb, err := imaging.ParseHex(colour)
+			if err != nil {
+				apierror.RespondCode(c, http.StatusBadRequest, apierror.InvalidParam, "colour must be a hex colour such as 1e3a8a", gin.H{
+					"request_param": colour,
+				})
+
+				return
+			}
+			lab := imaging.ToLab(rgb)
+			p.colour = &lab
+
+			p.distance = defaultColourDistance
+			if param := c.Query("colour_distance"); param != "" {
+				p.distance, err = strconv.ParseFloat(param, 64)
+				if err != nil || p.distance <= 0 {
+					apierror.RespondCode(c, http.StatusBadRequest, apierror.InvalidParam, "colour_distance must be a positive number", gin.H{
+						"request_param": param,
+					})
+
+					return
+				}
+			}
+		}
+
+		if c.Param("term") != "" || p.colour == nil {
+			q, err := search.Parse(c.Param("term"))
+			if err != nil {
+				syntaxErr := err.(*search.SyntaxError)
+				apierror.RespondCode(c, http.StatusBadRequest, apierror.InvalidQuery, syntaxErr.Message, gin.H{
+					"position": syntaxErr.Pos,
+					"token":    syntaxErr.Token,
+				})
+
+				return
+			}
+			p.query = q
+		}
+
+		term := p.query.Text()
+		language := search.Language(c.Query("lang"), c.GetHeader("Accept-Language"))
+		p.config = search.Config(language)
+
+		limit, set, ok := intQuery(c, "limit")
+		if !ok {
+			return
+		}
+		if !set || limit <= 0 {
+			limit = defaultSearchLimit
+		}
+		if limit > maxSearchLimit {
+			limit = maxSearchLimit
+		}
+
+		page := pageParam(c)
+
+		query, err := searchQuery(db, c, p, "")
+		if err != nil {
+			apierror.RespondCode(c, http.StatusBadRequest, apierror.InvalidParam, "century must be a year such as 1800", gin.H{
+				"request_param": c.Query("century"),
+			})
+
+			return
+		}
+
+		response := models.SearchResponse{
+			Results:  []models.SearchHit{},
+			Facets:   make(map[string][]models.FacetCount),
+			NextPage: page + limit,
+			Language: language,
+		}
+
+		if err := query.Session(&gorm.Session{}).Count(&response.Total).Error; err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		// close spellings cannot be narrowed by colour, so a colour search stays empty
+		if response.Total == 0 && term != "" && p.colour == nil {
+			if err := fuzzySearch(db, term, page, limit, &response); err != nil {
+				apierror.InternalError(c, err)
+
+				return
+			}
+
+			c.JSON(http.StatusOK, response)
+			return
+		}
+
+		selection := "s.*, ts_rank(d.document, plainto_tsquery(d.config, ?)) as rank, " +
+			"ts_headline(d.config, coalesce(nullif(s.\"Description\", ''), s.\"Title\"), plainto_tsquery(d.config, ?), ?) as headline"
+		order := "rank desc, s.\"ID\""
+		if p.colour != nil {
+			selection += ", pc.distance as colour_distance"
+			order = "rank desc, pc.distance, s.\"ID\""
+		}
+
+		err = query.Select(selection, term, term, headlineOptions).Order(order).Offset(page).Limit(limit).Scan(&response.Results).Error
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		for _, facet := range searchFacets {
+			facetQuery, _ := searchQuery(db, c, p, facet.param)
+
+			counts := []models.FacetCount{}
+			err := facetQuery.Select("cast(" + facet.expr + " as text) as value, count(*) as count").Where(
+				facet.expr + " is not null").Group(facet.expr).Order("count desc, value").Limit(facetSize).Scan(&counts).Error
+			if err != nil {
+				apierror.InternalError(c, err)
+
+				return
+			}
+
+			response.Facets[facet.param] = counts
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
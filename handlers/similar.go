@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"AT-BE/apierror"
+	"AT-BE/imaging"
+	"AT-BE/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultSimilarLimit = 12
+	maxSimilarLimit     = 50
+	// hashes further apart than this, out of imaging.MaxHashDistance, are not similar
+	maxSimilarDistance = 40
+)
+
+// Builds the index of image hashes used by SimilarArtworks from artwork_hashes. Hashes
+// stored by the hashes command after this runs are not seen until the next startup
+func LoadSimilarityIndex(db *gorm.DB) (*imaging.BKTree, error) {
+	tree := imaging.NewBKTree()
+
+	var batch []models.ArtworkHashes
+	err := db.Model(&models.ArtworkHashes{}).Order("artwork_id").FindInBatches(&batch, 1000, func(tx *gorm.DB, _ int) error {
+		for _, row := range batch {
+			tree.Add(row.Artwork_ID, imaging.Hash{P: uint64(row.P_Hash), D: uint64(row.D_Hash)})
+		}
+		return nil
+	}).Error
+
+	return tree, err
+}
+
+// Lists the artworks whose image looks most like the image of the artwork in the id param,
+// nearest first, using the hashes in index. The limit param caps the results
+func SimilarArtworks(db *gorm.DB, index *imaging.BKTree) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c, "id")
+		if !ok {
+			return
+		}
+
+		limit, set, ok := intQuery(c, "limit")
+		if !ok {
+			return
+		}
+		if !set || limit <= 0 {
+			limit = defaultSimilarLimit
+		}
+		if limit > maxSimilarLimit {
+			limit = maxSimilarLimit
+		}
+
+		similar := []models.SimilarArtwork{}
+
+		hash, indexed := index.Hash(id)
+		if !indexed {
+			exists, err := recordExists(db, &models.Artwork{}, id)
+			if err != nil {
+				apierror.InternalError(c, err)
+
+				return
+			}
+
+			if !exists {
+				apierror.Respond(c, http.StatusNotFound, "artwork could not be found", gin.H{
+					"request_param": c.Param("id"),
+				})
+
+				return
+			}
+
+			// the artwork has not been hashed yet
+			c.JSON(http.StatusOK, similar)
+			return
+		}
+
+		// one extra match to make up for the artwork itself
+		matches := index.Search(hash, maxSimilarDistance, limit+1)
+		ids := make([]int, 0, len(matches))
+		for _, match := range matches {
+			if match.ID != id {
+				ids = append(ids, match.ID)
+			}
+		}
+		if len(ids) == 0 {
+			c.JSON(http.StatusOK, similar)
+			return
+		}
+
+		var artworks []models.Searches
+		if err := db.Table("searches").Where("searches.\"ID\" in ?", ids).Find(&artworks).Error; err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		byID := make(map[string]models.Searches, len(artworks))
+		for _, artwork := range artworks {
+			byID[artwork.ID] = artwork
+		}
+
+		for _, match := range matches {
+			artwork, ok := byID[strconv.Itoa(match.ID)]
+			if ok && match.ID != id && len(similar) < limit {
+				similar = append(similar, models.SimilarArtwork{Searches: artwork, Distance: match.Distance})
+			}
+		}
+
+		c.JSON(http.StatusOK, similar)
+	}
+}
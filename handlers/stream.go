@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"AT-BE/apierror"
+	"AT-BE/broker"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// how often an idle stream sends a heartbeat so proxies keep the connection open
+const heartbeatInterval = 30 * time.Second
+
+// broker used to push events to clients connected to StreamEvents
+var events broker.Broker = broker.NewMemoryBroker()
+
+// Replaces the in-process broker, used by main to share events between instances
+func UseBroker(b broker.Broker) {
+	events = b
+}
+
+// Publishes an event, logging failures so the triggering request still succeeds
+func publish(topic string, eventType string, data interface{}) {
+	if err := events.Publish(topic, broker.Event{Type: eventType, Data: data}); err != nil {
+		log.Print(errors.Wrapf(err, "unable to publish %v to %v", eventType, topic))
+	}
+}
+
+// Streams the caller's notifications as server-sent events, along with changes to the
+// curations listed in the curations param (e.g. ?curations=1,2)
+func StreamEvents(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authID := c.GetInt("authID")
+		topics := []string{broker.UserTopic(authID)}
+
+		if curations := c.Query("curations"); curations != "" {
+			for _, param := range strings.Split(curations, ",") {
+				id, err := strconv.Atoi(strings.TrimSpace(param))
+				if err != nil {
+					apierror.RespondCode(c, http.StatusBadRequest, apierror.InvalidParam, "curations must be a comma separated list of IDs", gin.H{
+						"request_param": param,
+					})
+
+					return
+				}
+
+				if _, err := visibleCuration(db, id, authID); err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						apierror.Respond(c, http.StatusNotFound, "curation could not be found", gin.H{
+							"request_param": param,
+						})
+					} else {
+						apierror.InternalError(c, err)
+					}
+
+					return
+				}
+
+				topics = append(topics, broker.CurationTopic(id))
+			}
+		}
+
+		ch, unsubscribe := events.Subscribe(topics...)
+		defer unsubscribe()
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+
+		c.Writer.Header().Set("Cache-Control", "no-cache")
+		c.Writer.Header().Set("X-Accel-Buffering", "no")
+
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case e, ok := <-ch:
+				if !ok {
+					return false
+				}
+				c.SSEvent(e.Type, e.Data)
+				return true
+			case <-heartbeat.C:
+				c.SSEvent("heartbeat", time.Now().Unix())
+				return true
+			case <-c.Request.Context().Done():
+				return false
+			}
+		})
+	}
+}
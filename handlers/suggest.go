@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"AT-BE/apierror"
+	"AT-BE/models"
+	"AT-BE/search"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// lowest pg_trgm word_similarity accepted for fuzzy matches and suggestions
+	minSimilarity = 0.4
+
+	suggestSize = 10
+	// shortest input the suggest endpoint will complete
+	minSuggestLength = 2
+)
+
+// Runs fn in a transaction where the pg_trgm <% operator accepts word similarities of at least
+// minSimilarity. Unlike comparing word_similarity to a bound, <% can be served by the trigram
+// indexes on the accent folded titles and artist names
+func withSimilarityThreshold(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("select set_config('pg_trgm.word_similarity_threshold', ?, true)",
+			strconv.FormatFloat(minSimilarity, 'f', -1, 64)).Error
+		if err != nil {
+			return err
+		}
+
+		return fn(tx)
+	})
+}
+
+// artworks whose title or artist name is a close spelling of the term arg, one union branch per
+// indexed column
+const fuzzyMatches = `a.id in (
+	select t.id from artwork_migrate_artwork as t
+	where f_unaccent(?) <% f_unaccent(t.title)
+	union
+	select n.id from artwork_migrate_artwork as n
+	join artwork_migrate_artist as ar on ar.id = n.artist_id
+	where f_unaccent(?) <% f_unaccent(ar.name)
+)`
+
+// Fills response with artworks whose title or artist name is a close spelling of term, best
+// matches first, for searches such as "Rembrant" that full-text search cannot match. Accents
+// are ignored on both sides
+func fuzzySearch(db *gorm.DB, term string, page int, limit int, response *models.SearchResponse) error {
+	score := "greatest(word_similarity(f_unaccent(?), f_unaccent(coalesce(a.title, ''))), " +
+		"word_similarity(f_unaccent(?), f_unaccent(coalesce(ar.name, ''))))"
+
+	return withSimilarityThreshold(db, func(tx *gorm.DB) error {
+		query := tx.Table("searches as s").Joins(
+			"join artwork_migrate_artwork as a on a.id = s.\"ID\"").Joins(
+			"left join artwork_migrate_artist as ar on ar.id = a.artist_id").Where(
+			fuzzyMatches, term, term)
+
+		if err := query.Session(&gorm.Session{}).Count(&response.Total).Error; err != nil {
+			return err
+		}
+
+		response.Fuzzy = true
+
+		return query.Select("s.*, "+score+" as rank, s.\"Title\" as headline", term, term).Order(
+			"rank desc, s.\"ID\"").Offset(page).Limit(limit).Scan(&response.Results).Error
+	})
+}
+
+// Completes the q param with artist names and artwork titles as the user types. Prefix matches
+// come first, followed by close spellings scoring at least minSimilarity. Accents are ignored,
+// so "Durer" completes to "Albrecht Dürer". Each match is its own union branch so both can use
+// the trigram indexes
+func Suggest(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		q := strings.TrimSpace(c.Query("q"))
+		if len([]rune(q)) < minSuggestLength {
+			apierror.RespondCode(c, http.StatusBadRequest, apierror.InvalidParam, "q must be at least 2 characters", gin.H{
+				"request_param": q,
+			})
+
+			return
+		}
+
+		prefix := search.EscapeLike(q) + "%"
+
+		suggestions := []models.Suggestion{}
+		err := withSimilarityThreshold(db, func(tx *gorm.DB) error {
+			return tx.Raw(`
+				select value, kind, score from (
+					select distinct on (value, kind) value, kind, score from (
+						select ar.name as value, 'artist' as kind, 1 as score
+						from artwork_migrate_artist as ar
+						where f_unaccent(ar.name) ilike f_unaccent(@prefix)
+						union all
+						select ar.name, 'artist', word_similarity(f_unaccent(@q), f_unaccent(ar.name))
+						from artwork_migrate_artist as ar
+						where f_unaccent(@q) <% f_unaccent(ar.name)
+						union all
+						select a.title, 'title', 1
+						from artwork_migrate_artwork as a
+						where f_unaccent(a.title) ilike f_unaccent(@prefix)
+						union all
+						select a.title, 'title', word_similarity(f_unaccent(@q), f_unaccent(a.title))
+						from artwork_migrate_artwork as a
+						where f_unaccent(@q) <% f_unaccent(a.title)
+					) as matches
+					order by value, kind, score desc
+				) as best
+				order by score desc, length(value), value
+				limit @size`,
+				map[string]interface{}{"q": q, "prefix": prefix, "size": suggestSize},
+			).Scan(&suggestions).Error
+		})
+		if err != nil {
+			apierror.InternalError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, suggestions)
+	}
+}
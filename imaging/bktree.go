@@ -0,0 +1,105 @@
+package imaging
+
+import (
+	"sort"
+)
+
+// Match is an indexed ID and its distance from the searched hash
+type Match struct {
+	ID       int `json:"id"`
+	Distance int `json:"distance"`
+}
+
+type bkNode struct {
+	id       int
+	hash     Hash
+	children map[int]*bkNode
+}
+
+// BKTree indexes hashes by Hash.Distance so that near neighbours can be found without
+// comparing against every hash. It is not safe to Add while other goroutines Search
+type BKTree struct {
+	root   *bkNode
+	hashes map[int]Hash
+}
+
+func NewBKTree() *BKTree {
+	return &BKTree{hashes: make(map[int]Hash)}
+}
+
+// Len is the number of indexed IDs
+func (t *BKTree) Len() int {
+	return len(t.hashes)
+}
+
+// Hash returns the hash indexed for id
+func (t *BKTree) Hash(id int) (Hash, bool) {
+	h, ok := t.hashes[id]
+	return h, ok
+}
+
+// Add indexes the hash of id. Adding an ID a second time is ignored
+func (t *BKTree) Add(id int, hash Hash) {
+	if _, ok := t.hashes[id]; ok {
+		return
+	}
+	t.hashes[id] = hash
+
+	node := &bkNode{id: id, hash: hash, children: make(map[int]*bkNode)}
+	if t.root == nil {
+		t.root = node
+		return
+	}
+
+	current := t.root
+	for {
+		d := current.hash.Distance(hash)
+		child, ok := current.children[d]
+		if !ok {
+			current.children[d] = node
+			return
+		}
+		current = child
+	}
+}
+
+// Search returns the IDs whose hash is within maxDistance of hash, nearest first, then by ID.
+// At most limit matches are returned
+func (t *BKTree) Search(hash Hash, maxDistance int, limit int) []Match {
+	matches := []Match{}
+	if t.root == nil {
+		return matches
+	}
+
+	// by the triangle inequality, only children between d - maxDistance and d + maxDistance
+	// of a node can hold matches
+	stack := []*bkNode{t.root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		d := node.hash.Distance(hash)
+		if d <= maxDistance {
+			matches = append(matches, Match{ID: node.id, Distance: d})
+		}
+
+		for childDistance, child := range node.children {
+			if childDistance >= d-maxDistance && childDistance <= d+maxDistance {
+				stack = append(stack, child)
+			}
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Distance != matches[j].Distance {
+			return matches[i].Distance < matches[j].Distance
+		}
+		return matches[i].ID < matches[j].ID
+	})
+
+	if limit >= 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches
+}
@@ -0,0 +1,94 @@
+package imaging
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Lab is a colour in CIELAB, where straight line distance roughly matches how different two
+// colours look
+type Lab struct {
+	L float64 `json:"l"`
+	A float64 `json:"a"`
+	B float64 `json:"b"`
+}
+
+// D65 reference white
+const (
+	whiteX = 0.95047
+	whiteY = 1.0
+	whiteZ = 1.08883
+)
+
+// Distance is the CIE76 colour difference. Around 2 is just noticeable, and colours more than
+// 20 apart read as different colours
+func (l Lab) Distance(o Lab) float64 {
+	return math.Sqrt((l.L-o.L)*(l.L-o.L) + (l.A-o.A)*(l.A-o.A) + (l.B-o.B)*(l.B-o.B))
+}
+
+// undoes the sRGB gamma curve for a channel in 0..1
+func linear(c float64) float64 {
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+func labF(t float64) float64 {
+	const delta = 6.0 / 29.0
+	if t > delta*delta*delta {
+		return math.Cbrt(t)
+	}
+
+	return t/(3*delta*delta) + 4.0/29.0
+}
+
+// ToLab converts an sRGB colour to CIELAB under D65. Alpha is ignored
+func ToLab(c color.Color) Lab {
+	r, g, b, a := c.RGBA()
+	if a == 0 {
+		return Lab{}
+	}
+
+	// RGBA is alpha premultiplied, undo it before converting
+	R := linear(float64(r) / float64(a))
+	G := linear(float64(g) / float64(a))
+	B := linear(float64(b) / float64(a))
+
+	x := labF((0.4124564*R + 0.3575761*G + 0.1804375*B) / whiteX)
+	y := labF((0.2126729*R + 0.7151522*G + 0.0721750*B) / whiteY)
+	z := labF((0.0193339*R + 0.1191920*G + 0.9503041*B) / whiteZ)
+
+	return Lab{L: 116*y - 16, A: 500 * (x - y), B: 200 * (y - z)}
+}
+
+// ParseHex reads a colour such as #1e3a8a, 1e3a8a or #fff
+func ParseHex(s string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return color.RGBA{}, errors.Errorf("%q is not a hex colour", s)
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, errors.Errorf("%q is not a hex colour", s)
+	}
+
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}, nil
+}
+
+// Hex formats a colour as #rrggbb
+func Hex(c color.Color) string {
+	r, g, b, _ := color.RGBAModel.Convert(c).RGBA()
+
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
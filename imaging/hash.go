@@ -0,0 +1,137 @@
+package imaging
+
+import (
+	"image"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// Hash is a perceptual fingerprint of an image. Resized, recompressed or slightly recoloured
+// copies of an image hash to nearby values, so the Hamming distance between two hashes says
+// how alike the images look
+type Hash struct {
+	// DCT based hash, robust to scaling and contrast changes
+	P uint64 `json:"phash"`
+	// gradient hash, sensitive to composition
+	D uint64 `json:"dhash"`
+}
+
+// MaxHashDistance is the largest possible Distance, when every bit differs
+const MaxHashDistance = 128
+
+// Distance is the number of differing bits across both hashes. It is a metric, so hashes can
+// be indexed in a BKTree
+func (h Hash) Distance(o Hash) int {
+	return bits.OnesCount64(h.P^o.P) + bits.OnesCount64(h.D^o.D)
+}
+
+// Shrinks img to w by h luminance values, averaging the pixels that fall in each cell
+func grayscale(img image.Image, w int, h int) [][]float64 {
+	bounds := img.Bounds()
+	sums := make([][]float64, h)
+	counts := make([][]int, h)
+	for y := range sums {
+		sums[y] = make([]float64, w)
+		counts[y] = make([]int, w)
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		cy := (y - bounds.Min.Y) * h / bounds.Dy()
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			cx := (x - bounds.Min.X) * w / bounds.Dx()
+			r, g, b, _ := img.At(x, y).RGBA()
+			sums[cy][cx] += 0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8)
+			counts[cy][cx]++
+		}
+	}
+
+	for y := range sums {
+		for x := range sums[y] {
+			if counts[y][x] > 0 {
+				sums[y][x] /= float64(counts[y][x])
+			}
+		}
+	}
+
+	return sums
+}
+
+// dHash sets a bit for each of the 64 neighbouring pairs in a 9 by 8 thumbnail where the
+// left pixel is brighter than the right
+func dHash(img image.Image) uint64 {
+	pixels := grayscale(img, 9, 8)
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if pixels[y][x] > pixels[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+
+	return hash
+}
+
+// pHash takes the lowest 8 by 8 frequencies of a 32 by 32 thumbnail's discrete cosine
+// transform, setting a bit for each one above the median
+func pHash(img image.Image) uint64 {
+	const size = 32
+	pixels := grayscale(img, size, size)
+
+	// separable 2D DCT-II, rows then columns, only the frequencies that are kept
+	cosines := make([][]float64, 8)
+	for u := range cosines {
+		cosines[u] = make([]float64, size)
+		for x := 0; x < size; x++ {
+			cosines[u][x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / (2 * size))
+		}
+	}
+
+	rows := make([][]float64, size)
+	for y := 0; y < size; y++ {
+		rows[y] = make([]float64, 8)
+		for u := 0; u < 8; u++ {
+			for x := 0; x < size; x++ {
+				rows[y][u] += pixels[y][x] * cosines[u][x]
+			}
+		}
+	}
+
+	var coefficients []float64
+	for v := 0; v < 8; v++ {
+		for u := 0; u < 8; u++ {
+			var sum float64
+			for y := 0; y < size; y++ {
+				sum += rows[y][u] * cosines[v][y]
+			}
+			coefficients = append(coefficients, sum)
+		}
+	}
+
+	// the first coefficient is the average brightness, which would skew the median
+	sorted := append([]float64{}, coefficients[1:]...)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+
+	var hash uint64
+	for _, coefficient := range coefficients {
+		hash <<= 1
+		if coefficient > median {
+			hash |= 1
+		}
+	}
+
+	return hash
+}
+
+// PerceptualHash computes both hashes of img
+func PerceptualHash(img image.Image) Hash {
+	if img.Bounds().Empty() {
+		return Hash{}
+	}
+
+	return Hash{P: pHash(img), D: dHash(img)}
+}
@@ -0,0 +1,211 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"sort"
+)
+
+const (
+	// pixels sampled along each side of the image, larger images are sampled on a grid
+	sampleSide = 64
+	// k-means iterations before giving up on convergence
+	maxIterations = 10
+	// clusters closer than this are merged, so a palette does not repeat a colour
+	mergeDistance = 10
+)
+
+// Swatch is one colour of a palette and the share of the image it covers
+type Swatch struct {
+	Colour color.RGBA
+	Lab    Lab
+	Weight float64
+}
+
+type sample struct {
+	rgb [3]float64
+	lab Lab
+}
+
+type cluster struct {
+	centre Lab
+	rgb    [3]float64
+	count  int
+}
+
+// samples opaque pixels on a grid of at most sampleSide by sampleSide
+func samples(img image.Image) []sample {
+	bounds := img.Bounds()
+	stepX := bounds.Dx()/sampleSide + 1
+	stepY := bounds.Dy()/sampleSide + 1
+
+	var out []sample
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += stepY {
+		for x := bounds.Min.X; x < bounds.Max.X; x += stepX {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A < 128 {
+				continue
+			}
+
+			out = append(out, sample{
+				rgb: [3]float64{float64(c.R), float64(c.G), float64(c.B)},
+				lab: ToLab(color.RGBA{R: c.R, G: c.G, B: c.B, A: 255}),
+			})
+		}
+	}
+
+	return out
+}
+
+// starts the clusters at the k most common colours after dropping each channel to 3 bits,
+// which keeps palettes deterministic and avoids starting on outliers
+func seedClusters(points []sample, k int) []cluster {
+	buckets := make(map[int]*cluster)
+	for _, p := range points {
+		key := int(p.rgb[0])>>5<<6 | int(p.rgb[1])>>5<<3 | int(p.rgb[2])>>5
+		b, ok := buckets[key]
+		if !ok {
+			b = &cluster{}
+			buckets[key] = b
+		}
+		b.centre.L += p.lab.L
+		b.centre.A += p.lab.A
+		b.centre.B += p.lab.B
+		b.count++
+	}
+
+	keys := make([]int, 0, len(buckets))
+	for key := range buckets {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if buckets[keys[i]].count != buckets[keys[j]].count {
+			return buckets[keys[i]].count > buckets[keys[j]].count
+		}
+		return keys[i] < keys[j]
+	})
+
+	if len(keys) > k {
+		keys = keys[:k]
+	}
+
+	clusters := make([]cluster, len(keys))
+	for i, key := range keys {
+		b := buckets[key]
+		n := float64(b.count)
+		clusters[i].centre = Lab{L: b.centre.L / n, A: b.centre.A / n, B: b.centre.B / n}
+	}
+
+	return clusters
+}
+
+// Palette finds up to k dominant colours of img by k-means clustering its pixels in CIELAB.
+// Swatches are sorted by weight, largest first, and their weights sum to 1. Transparent
+// pixels are ignored, and an empty palette is returned when every pixel is transparent
+func Palette(img image.Image, k int) []Swatch {
+	points := samples(img)
+	if len(points) == 0 || k <= 0 {
+		return []Swatch{}
+	}
+
+	clusters := seedClusters(points, k)
+	assigned := make([]int, len(points))
+	for i := range assigned {
+		assigned[i] = -1
+	}
+
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		changed := false
+		for i, p := range points {
+			nearest := 0
+			for j := range clusters {
+				if p.lab.Distance(clusters[j].centre) < p.lab.Distance(clusters[nearest].centre) {
+					nearest = j
+				}
+			}
+			if assigned[i] != nearest {
+				assigned[i] = nearest
+				changed = true
+			}
+		}
+
+		sums := make([]cluster, len(clusters))
+		for i, p := range points {
+			s := &sums[assigned[i]]
+			s.centre.L += p.lab.L
+			s.centre.A += p.lab.A
+			s.centre.B += p.lab.B
+			for c := range p.rgb {
+				s.rgb[c] += p.rgb[c]
+			}
+			s.count++
+		}
+
+		for j := range clusters {
+			n := float64(sums[j].count)
+			clusters[j].count = sums[j].count
+			if n == 0 {
+				continue
+			}
+			clusters[j].centre = Lab{L: sums[j].centre.L / n, A: sums[j].centre.A / n, B: sums[j].centre.B / n}
+			clusters[j].rgb = [3]float64{sums[j].rgb[0] / n, sums[j].rgb[1] / n, sums[j].rgb[2] / n}
+		}
+
+		if !changed {
+			break
+		}
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return clusters[i].count > clusters[j].count
+	})
+
+	// folds each cluster into a larger one it cannot be told apart from
+	var merged []cluster
+	for _, cl := range clusters {
+		if cl.count == 0 {
+			continue
+		}
+
+		into := -1
+		for j := range merged {
+			if merged[j].centre.Distance(cl.centre) < mergeDistance {
+				into = j
+				break
+			}
+		}
+		if into < 0 {
+			merged = append(merged, cl)
+			continue
+		}
+
+		m := &merged[into]
+		total := float64(m.count + cl.count)
+		share := float64(cl.count) / total
+		m.centre = Lab{
+			L: m.centre.L + (cl.centre.L-m.centre.L)*share,
+			A: m.centre.A + (cl.centre.A-m.centre.A)*share,
+			B: m.centre.B + (cl.centre.B-m.centre.B)*share,
+		}
+		for c := range m.rgb {
+			m.rgb[c] += (cl.rgb[c] - m.rgb[c]) * share
+		}
+		m.count += cl.count
+	}
+
+	palette := []Swatch{}
+	for _, cl := range merged {
+		rgb := color.RGBA{R: uint8(cl.rgb[0] + 0.5), G: uint8(cl.rgb[1] + 0.5), B: uint8(cl.rgb[2] + 0.5), A: 255}
+		palette = append(palette, Swatch{
+			Colour: rgb,
+			Lab:    ToLab(rgb),
+			Weight: float64(cl.count) / float64(len(points)),
+		})
+	}
+
+	sort.SliceStable(palette, func(i, j int) bool {
+		return palette[i].Weight > palette[j].Weight
+	})
+
+	return palette
+}
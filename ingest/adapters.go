@@ -0,0 +1,155 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"AT-BE/models"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// SourceAdapter reads the open data dumps of one museum. Each record holds an artwork along
+// with its artist, which Parse normalises onto the catalog models. Adapters only read files,
+// so they can be tested against fixture dumps
+type SourceAdapter interface {
+	// Source is the museum the dumps come from. Its Abriviation scopes the external IDs
+	Source() models.Source
+	// Each calls fn with every record in the dump at path
+	Each(path string, fn func(Record) error) error
+	// Parse maps a record to its artist and artwork
+	Parse(r Record) (ArtistRow, ArtworkRow, error)
+}
+
+// Adapters are the source adapters by the name passed to at-be ingest -adapter
+var Adapters = map[string]SourceAdapter{
+	"aic":         AIC{},
+	"met":         Met{},
+	"rijksmuseum": Rijksmuseum{},
+}
+
+// AdapterNames lists the adapters, sorted
+func AdapterNames() []string {
+	names := make([]string, 0, len(Adapters))
+	for name := range Adapters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// artist used for works that do not credit one, as every artwork needs an artist
+var unknownArtist = ArtistRow{External_ID: "unknown", Artist: models.Artist{Name: "Unknown"}}
+
+// UseAdapterSource sets the source to the adapter's, adding it when it does not exist yet
+func (in *Ingester) UseAdapterSource(adapter SourceAdapter) error {
+	source := adapter.Source()
+
+	result := in.db.Where("abriviation = ?", source.Abriviation).Limit(1).Find(&in.Source)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	source.Last_Modified = time.Now()
+	if err := in.db.Create(&source).Error; err != nil {
+		return errors.Wrapf(err, "unable to add source %v", source.Abriviation)
+	}
+	in.Source = source
+
+	return nil
+}
+
+// Adapted upserts the artworks and artists in the adapter's dump at path, see saveArtist and
+// saveArtwork. Counts are of the artworks
+func (in *Ingester) Adapted(adapter SourceAdapter, path string) (Counts, error) {
+	if err := in.UseAdapterSource(adapter); err != nil {
+		return Counts{}, err
+	}
+
+	return in.load(path, adapter.Each, func(tx *gorm.DB, r Record) (bool, error) {
+		artist, artwork, err := adapter.Parse(r)
+		if err != nil {
+			return false, err
+		}
+
+		if _, err := in.saveArtist(tx, artist); err != nil {
+			return false, errors.Wrap(err, "artist")
+		}
+
+		return in.saveArtwork(tx, artwork)
+	})
+}
+
+// Reads a JSON dump that is either an array of records, an object holding the array under
+// key, or a single record. JSON lines files are read with one record per line
+func eachJSONItems(path string, key string, fn func(Record) error) error {
+	if strings.ToLower(filepath.Ext(path)) == ".jsonl" {
+		return Each(path, fn)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for {
+		b, err := reader.Peek(1)
+		if err != nil {
+			return errors.Wrap(err, "empty dump")
+		}
+		if !bytes.Contains([]byte(" \t\r\n"), b) {
+			break
+		}
+		reader.ReadByte()
+	}
+
+	if b, _ := reader.Peek(1); b[0] == '[' {
+		return eachJSON(reader, fn)
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.NewDecoder(reader).Decode(&object); err != nil {
+		return err
+	}
+
+	items, ok := object[key]
+	if !ok {
+		return fn(jsonRecord(1, object))
+	}
+
+	var list []map[string]json.RawMessage
+	if err := json.Unmarshal(items, &list); err != nil {
+		return fmt.Errorf("%v is not an array of objects", key)
+	}
+	for i, item := range list {
+		if err := fn(jsonRecord(i+1, item)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Unmarshals the JSON text a nested value was kept as, leaving v unset when the field is empty
+func nested(r Record, name string, v interface{}) error {
+	value := r.Get(name)
+	if value == "" {
+		return nil
+	}
+
+	return errors.Wrap(json.Unmarshal([]byte(value), v), name)
+}
@@ -0,0 +1,75 @@
+package ingest
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"AT-BE/models"
+
+	"github.com/pkg/errors"
+)
+
+// AIC reads artworks from the Art Institute of Chicago: API responses holding a data array,
+// the one file per artwork of its data dump, or JSON lines
+type AIC struct{}
+
+// IIIF image server the image_id of an artwork refers to
+const aicIIIF = "https://www.artic.edu/iiif/2/"
+
+var htmlTags = regexp.MustCompile(`<[^>]*>`)
+
+func (AIC) Source() models.Source {
+	return models.Source{Source_Name: "Art Institute of Chicago", Abriviation: "AIC"}
+}
+
+func (AIC) Each(path string, fn func(Record) error) error {
+	return eachJSONItems(path, "data", fn)
+}
+
+func (AIC) Parse(r Record) (ArtistRow, ArtworkRow, error) {
+	artist := ArtistRow{
+		External_ID: r.Get("artist_id"),
+		Artist:      models.Artist{Name: r.Get("artist_title")},
+	}
+
+	// artist_display is the name, then a line such as "Dutch, 1853–1890"
+	var bio, nationality string
+	if lines := strings.SplitN(r.Get("artist_display"), "\n", 2); len(lines) == 2 {
+		bio = strings.TrimSpace(lines[1])
+		nationality = strings.TrimSpace(strings.Split(bio, ",")[0])
+	}
+	artist.Artist.Description = bio
+
+	if artist.External_ID == "" || artist.Artist.Name == "" {
+		artist = unknownArtist
+	}
+
+	artwork := ArtworkRow{
+		External_ID:        r.Get("id"),
+		Artist_External_ID: artist.External_ID,
+		Artwork: models.Artwork{
+			Title:           r.Get("title"),
+			Nationality:     nationality,
+			Artist_Bio:      bio,
+			Desc:            strings.TrimSpace(html.UnescapeString(htmlTags.ReplaceAllString(r.Get("description"), ""))),
+			Nation:          r.Get("place_of_origin"),
+			Medium:          r.Get("medium_display"),
+			Date_of_Release: r.Get("date_display"),
+		},
+	}
+
+	if id := r.Get("image_id"); id != "" {
+		artwork.Artwork.Image = aicIIIF + id + "/full/843,/0/default.jpg"
+		artwork.Artwork.Image_Small = aicIIIF + id + "/full/200,/0/default.jpg"
+	}
+
+	switch {
+	case artwork.External_ID == "":
+		return artist, artwork, errors.New("id is required")
+	case artwork.Artwork.Title == "":
+		return artist, artwork, errors.New("title is required")
+	}
+
+	return artist, artwork, nil
+}
@@ -0,0 +1,301 @@
+package ingest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"AT-BE/models"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// Counts are what happened to the rows of one dump
+type Counts struct {
+	Inserted int
+	Updated  int
+	Rejected int
+	// file listing the rejected rows, empty when none were
+	Errors string
+}
+
+// Ingester upserts dumps into the catalog tables. Artists and artworks belong to Source, whose
+// IDs for them are kept in external_ids so that loading a newer dump updates the same rows
+type Ingester struct {
+	db *gorm.DB
+	// directory for the error files, next to each dump when empty
+	ErrorDir string
+	Source   models.Source
+}
+
+func New(db *gorm.DB, errorDir string) *Ingester {
+	return &Ingester{db: db, ErrorDir: errorDir}
+}
+
+// UseSource sets the source artists and artworks are ingested for by its abbreviation
+func (in *Ingester) UseSource(abbreviation string) error {
+	result := in.db.Where("abriviation = ?", abbreviation).Limit(1).Find(&in.Source)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("source %q does not exist, load it with -sources first", abbreviation)
+	}
+
+	return nil
+}
+
+// Writes the rejected rows of a dump as CSV with the line, the error and the row as JSON. The
+// file is only created once a row is rejected
+type errorFile struct {
+	path   string
+	file   *os.File
+	writer *csv.Writer
+}
+
+func (e *errorFile) add(r Record, rowErr error) error {
+	if e.file == nil {
+		file, err := os.Create(e.path)
+		if err != nil {
+			return err
+		}
+		e.file = file
+		e.writer = csv.NewWriter(file)
+
+		if err := e.writer.Write([]string{"line", "error", "record"}); err != nil {
+			return err
+		}
+	}
+
+	fields, _ := json.Marshal(r.Fields)
+
+	return e.writer.Write([]string{strconv.Itoa(r.Line), rowErr.Error(), string(fields)})
+}
+
+func (e *errorFile) close() error {
+	if e.file == nil {
+		return nil
+	}
+
+	e.writer.Flush()
+	if err := e.writer.Error(); err != nil {
+		e.file.Close()
+		return err
+	}
+
+	return e.file.Close()
+}
+
+// Calls save with every record of the dump at path, read by each, counting the rows it inserted
+// and updated. Rows that fail to parse or save are rejected and written to the dump's error file
+func (in *Ingester) load(path string, each func(string, func(Record) error) error, save func(tx *gorm.DB, r Record) (bool, error)) (Counts, error) {
+	dir := in.ErrorDir
+	if dir == "" {
+		dir = filepath.Dir(path)
+	}
+	rejected := &errorFile{path: filepath.Join(dir, filepath.Base(path)+".errors.csv")}
+
+	// an error file left from an earlier run would no longer describe the dump
+	if err := os.Remove(rejected.path); err != nil && !os.IsNotExist(err) {
+		return Counts{}, err
+	}
+
+	var counts Counts
+	err := each(path, func(r Record) error {
+		rowErr := r.Err
+		if rowErr == nil {
+			var inserted bool
+			rowErr = in.db.Transaction(func(tx *gorm.DB) error {
+				var err error
+				inserted, err = save(tx, r)
+				return err
+			})
+
+			if rowErr == nil && inserted {
+				counts.Inserted++
+			} else if rowErr == nil {
+				counts.Updated++
+			}
+		}
+
+		if rowErr != nil {
+			counts.Rejected++
+			return rejected.add(r, rowErr)
+		}
+
+		return nil
+	})
+
+	if closeErr := rejected.close(); err == nil {
+		err = closeErr
+	}
+	if rejected.file != nil {
+		counts.Errors = rejected.path
+	}
+
+	return counts, err
+}
+
+// Looks up the catalog ID of the source's kind item with externalID, returning 0 when it has
+// not been ingested before
+func (in *Ingester) itemID(tx *gorm.DB, kind string, externalID string) (int, error) {
+	var ext models.ExternalIDs
+	err := tx.Where("kind = ? and source_id = ? and external_id = ?", kind, in.Source.ID, externalID).Limit(1).Find(&ext).Error
+
+	return ext.Item_ID, err
+}
+
+// Records that the source's kind item with externalID is the catalog row id
+func (in *Ingester) saveItemID(tx *gorm.DB, kind string, externalID string, id int) error {
+	return tx.Create(&models.ExternalIDs{
+		Kind:        kind,
+		Source_ID:   in.Source.ID,
+		External_ID: externalID,
+		Item_ID:     id,
+	}).Error
+}
+
+// Sources upserts the sources in the dump at path by abbreviation
+func (in *Ingester) Sources(path string) (Counts, error) {
+	return in.load(path, Each, func(tx *gorm.DB, r Record) (bool, error) {
+		source, err := ParseSource(r)
+		if err != nil {
+			return false, err
+		}
+		source.Last_Modified = time.Now()
+
+		var existing models.Source
+		result := tx.Where("abriviation = ?", source.Abriviation).Limit(1).Find(&existing)
+		if result.Error != nil {
+			return false, result.Error
+		}
+		if result.RowsAffected > 0 {
+			source.ID = existing.ID
+			return false, tx.Save(&source).Error
+		}
+
+		return true, tx.Create(&source).Error
+	})
+}
+
+// Eras upserts the eras in the dump at path by name
+func (in *Ingester) Eras(path string) (Counts, error) {
+	return in.load(path, Each, func(tx *gorm.DB, r Record) (bool, error) {
+		era, err := ParseEra(r)
+		if err != nil {
+			return false, err
+		}
+		era.Last_Modified = time.Now()
+
+		var existing models.Era
+		result := tx.Where("era_name = ?", era.Era_Name).Limit(1).Find(&existing)
+		if result.Error != nil {
+			return false, result.Error
+		}
+		if result.RowsAffected > 0 {
+			era.ID = existing.ID
+			return false, tx.Save(&era).Error
+		}
+
+		return true, tx.Create(&era).Error
+	})
+}
+
+// Upserts an artist by the source's ID for them. Artists in an era that does not exist are
+// rejected
+func (in *Ingester) saveArtist(tx *gorm.DB, row ArtistRow) (bool, error) {
+	row.Artist.Last_Modified = time.Now()
+
+	if row.Artist.Era != "" {
+		var eras int64
+		if err := tx.Model(&models.Era{}).Where("era_name = ?", row.Artist.Era).Count(&eras).Error; err != nil {
+			return false, err
+		}
+		if eras == 0 {
+			return false, fmt.Errorf("era %q does not exist", row.Artist.Era)
+		}
+	}
+
+	id, err := in.itemID(tx, models.ExternalArtist, row.External_ID)
+	if err != nil {
+		return false, err
+	}
+	if id != 0 {
+		row.Artist.ID = id
+		return false, tx.Save(&row.Artist).Error
+	}
+
+	if err := tx.Create(&row.Artist).Error; err != nil {
+		return false, err
+	}
+
+	return true, in.saveItemID(tx, models.ExternalArtist, row.External_ID, row.Artist.ID)
+}
+
+// Upserts an artwork by the source's ID for it. Artworks whose artist has not been ingested
+// from the same source are rejected
+func (in *Ingester) saveArtwork(tx *gorm.DB, row ArtworkRow) (bool, error) {
+	row.Artwork.Last_Modified = time.Now()
+	row.Artwork.Source_ID = in.Source.ID
+
+	artistID, err := in.itemID(tx, models.ExternalArtist, row.Artist_External_ID)
+	if err != nil {
+		return false, err
+	}
+	if artistID == 0 {
+		return false, fmt.Errorf("artist %q has not been ingested", row.Artist_External_ID)
+	}
+	row.Artwork.Artist_ID = artistID
+
+	id, err := in.itemID(tx, models.ExternalArtwork, row.External_ID)
+	if err != nil {
+		return false, err
+	}
+	if id != 0 {
+		row.Artwork.ID = id
+		return false, tx.Save(&row.Artwork).Error
+	}
+
+	if err := tx.Create(&row.Artwork).Error; err != nil {
+		return false, err
+	}
+
+	return true, in.saveItemID(tx, models.ExternalArtwork, row.External_ID, row.Artwork.ID)
+}
+
+// Artists upserts the artists in the dump at path, see saveArtist
+func (in *Ingester) Artists(path string) (Counts, error) {
+	if in.Source.ID == 0 {
+		return Counts{}, errors.New("a source is required to ingest artists")
+	}
+
+	return in.load(path, Each, func(tx *gorm.DB, r Record) (bool, error) {
+		row, err := ParseArtist(r)
+		if err != nil {
+			return false, err
+		}
+
+		return in.saveArtist(tx, row)
+	})
+}
+
+// Artworks upserts the artworks in the dump at path, see saveArtwork
+func (in *Ingester) Artworks(path string) (Counts, error) {
+	if in.Source.ID == 0 {
+		return Counts{}, errors.New("a source is required to ingest artworks")
+	}
+
+	return in.load(path, Each, func(tx *gorm.DB, r Record) (bool, error) {
+		row, err := ParseArtwork(r)
+		if err != nil {
+			return false, err
+		}
+
+		return in.saveArtwork(tx, row)
+	})
+}
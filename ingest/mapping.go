@@ -0,0 +1,122 @@
+package ingest
+
+import (
+	"strings"
+
+	"AT-BE/models"
+
+	"github.com/pkg/errors"
+)
+
+// ArtworkRow is an artwork read from a dump along with the source's IDs for it and its artist
+type ArtworkRow struct {
+	External_ID        string
+	Artist_External_ID string
+	Artwork            models.Artwork
+}
+
+// ArtistRow is an artist read from a dump along with the source's ID for them
+type ArtistRow struct {
+	External_ID string
+	Artist      models.Artist
+}
+
+// Field names accepted for each column, in order of preference. Dumps use the catalog's
+// own column names, with a few common alternatives
+var (
+	externalIDFields       = []string{"external_id", "id", "object_id"}
+	artistExternalIDFields = []string{"artist_external_id", "artist_id"}
+	nameFields             = []string{"name", "source_name", "era_name"}
+	descriptionFields      = []string{"desc", "description"}
+	dateFields             = []string{"date_of_release", "date"}
+	imageFields            = []string{"image", "image_url"}
+	imageSmallFields       = []string{"image_small", "thumbnail"}
+)
+
+// ParseSource maps a record with an abbreviation and a name to a source
+func ParseSource(r Record) (models.Source, error) {
+	source := models.Source{
+		Source_Name: r.Get(nameFields...),
+		Abriviation: strings.ToUpper(r.Get("abbreviation", "abriviation")),
+	}
+
+	switch {
+	case source.Abriviation == "":
+		return source, errors.New("abbreviation is required")
+	case source.Source_Name == "":
+		return source, errors.New("name is required")
+	}
+
+	return source, nil
+}
+
+// ParseEra maps a record with a name to an era
+func ParseEra(r Record) (models.Era, error) {
+	era := models.Era{Era_Name: r.Get(nameFields...)}
+	if era.Era_Name == "" {
+		return era, errors.New("name is required")
+	}
+
+	return era, nil
+}
+
+// ParseArtist maps a record with an external ID and a name to an artist. Era is the era's
+// name, and has to exist when the artist is saved
+func ParseArtist(r Record) (ArtistRow, error) {
+	row := ArtistRow{
+		External_ID: r.Get(externalIDFields...),
+		Artist: models.Artist{
+			Name:        r.Get(nameFields...),
+			Description: r.Get(descriptionFields...),
+			Era:         r.Get("era"),
+			Gender:      r.Get("gender"),
+		},
+	}
+
+	switch {
+	case row.External_ID == "":
+		return row, errors.New("external_id is required")
+	case row.Artist.Name == "":
+		return row, errors.New("name is required")
+	}
+
+	return row, nil
+}
+
+// ParseArtwork maps a record with an external ID, a title and its artist's external ID to an
+// artwork. The artist has to have been ingested from the same source
+func ParseArtwork(r Record) (ArtworkRow, error) {
+	row := ArtworkRow{
+		External_ID:        r.Get(externalIDFields...),
+		Artist_External_ID: r.Get(artistExternalIDFields...),
+		Artwork: models.Artwork{
+			Title:           r.Get("title"),
+			Nationality:     r.Get("nationality"),
+			Artist_Bio:      r.Get("artist_bio"),
+			Desc:            r.Get(descriptionFields...),
+			Culture:         r.Get("culture"),
+			Gender:          r.Get("gender"),
+			Nation:          r.Get("nation"),
+			Medium:          r.Get("medium"),
+			Date_of_Release: r.Get(dateFields...),
+			Image:           r.Get(imageFields...),
+			Image_Small:     r.Get(imageSmallFields...),
+		},
+	}
+
+	switch {
+	case row.External_ID == "":
+		return row, errors.New("external_id is required")
+	case row.Artwork.Title == "":
+		return row, errors.New("title is required")
+	case row.Artist_External_ID == "":
+		return row, errors.New("artist_external_id is required")
+	}
+
+	// the small image falls back to the full one so the work can still be shown in grids
+	if row.Artwork.Image_Small == "" {
+		row.Artwork.Image_Small = row.Artwork.Image
+	}
+
+	return row, nil
+}
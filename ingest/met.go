@@ -0,0 +1,74 @@
+package ingest
+
+import (
+	"strings"
+
+	"AT-BE/models"
+
+	"github.com/pkg/errors"
+)
+
+// Met reads the Metropolitan Museum of Art's Open Access CSV (MetObjects.csv), as well as
+// objects saved from its collection API, which add image URLs the CSV does not have
+type Met struct{}
+
+func (Met) Source() models.Source {
+	return models.Source{Source_Name: "The Metropolitan Museum of Art", Abriviation: "MET"}
+}
+
+func (Met) Each(path string, fn func(Record) error) error {
+	return Each(path, fn)
+}
+
+// the Met lists every artist of a work in one field, separated by |
+func firstOf(value string) string {
+	return strings.TrimSpace(strings.Split(value, "|")[0])
+}
+
+func (Met) Parse(r Record) (ArtistRow, ArtworkRow, error) {
+	artist := ArtistRow{
+		Artist: models.Artist{
+			Name:        firstOf(r.Get("Artist Display Name", "artistDisplayName")),
+			Description: firstOf(r.Get("Artist Display Bio", "artistDisplayBio")),
+			Gender:      firstOf(r.Get("Artist Gender", "artistGender")),
+		},
+	}
+
+	// artists are identified by their ULAN or Wikidata record where they have one
+	artist.External_ID = firstOf(r.Get("Artist ULAN URL", "artistULAN_URL"))
+	if artist.External_ID == "" {
+		artist.External_ID = firstOf(r.Get("Artist Wikidata URL", "artistWikidata_URL"))
+	}
+	if artist.External_ID == "" && artist.Artist.Name != "" {
+		artist.External_ID = "name:" + artist.Artist.Name
+	}
+	if artist.Artist.Name == "" {
+		artist = unknownArtist
+	}
+
+	artwork := ArtworkRow{
+		External_ID:        r.Get("Object ID", "objectID"),
+		Artist_External_ID: artist.External_ID,
+		Artwork: models.Artwork{
+			Title:           r.Get("Title", "title"),
+			Nationality:     firstOf(r.Get("Artist Nationality", "artistNationality")),
+			Artist_Bio:      artist.Artist.Description,
+			Culture:         r.Get("Culture", "culture"),
+			Gender:          artist.Artist.Gender,
+			Nation:          r.Get("Country", "country"),
+			Medium:          r.Get("Medium", "medium"),
+			Date_of_Release: r.Get("Object Date", "objectDate"),
+			Image:           r.Get("primaryImage"),
+			Image_Small:     r.Get("primaryImageSmall", "primaryImage"),
+		},
+	}
+
+	switch {
+	case artwork.External_ID == "":
+		return artist, artwork, errors.New("Object ID is required")
+	case artwork.Artwork.Title == "":
+		return artist, artwork, errors.New("Title is required")
+	}
+
+	return artist, artwork, nil
+}
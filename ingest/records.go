@@ -0,0 +1,176 @@
+// Package ingest loads museum open data dumps into the catalog tables. Dumps are read as
+// records of named fields, mapped onto the catalog models and upserted by the IDs the source
+// gives its artworks and artists
+package ingest
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Record is one row of a dump. Line is the CSV line or JSON lines line it was read from, or
+// its position in a JSON array, counting from 1. Err is set when the row itself could not be
+// parsed, leaving Fields empty
+type Record struct {
+	Line   int
+	Fields map[string]string
+	Err    error
+}
+
+// Get returns the first non-empty field out of names, trimmed
+func (r Record) Get(names ...string) string {
+	for _, name := range names {
+		if v := strings.TrimSpace(r.Fields[name]); v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// Formats lists the dump formats by file extension
+var Formats = []string{".csv", ".json", ".jsonl"}
+
+// Each calls fn with every record in the dump at path, read according to its extension: CSV
+// with a header row, a JSON array of objects, or JSON lines with one object per line. Nested
+// JSON values are kept as JSON text. Reading stops at the first error returned by fn
+func Each(path string, fn func(Record) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		return eachCSV(file, fn)
+	case ".json":
+		return eachJSON(file, fn)
+	case ".jsonl":
+		return eachJSONLines(file, fn)
+	default:
+		return fmt.Errorf("unknown dump format %q, expected one of %v", ext, strings.Join(Formats, ", "))
+	}
+}
+
+func eachCSV(r io.Reader, fn func(Record) error) error {
+	reader := csv.NewReader(r)
+	// rows missing trailing fields are still read, see the length check below
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return errors.Wrap(err, "header")
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))
+	}
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			if err := fn(Record{Line: parseErr.StartLine, Err: err}); err != nil {
+				return err
+			}
+
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		line, _ := reader.FieldPos(0)
+		record := Record{Line: line, Fields: make(map[string]string, len(header))}
+		for i, name := range header {
+			if i < len(row) {
+				record.Fields[name] = row[i]
+			}
+		}
+
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
+
+// Converts a JSON object to a record, keeping nested values as JSON text
+func jsonRecord(line int, object map[string]json.RawMessage) Record {
+	record := Record{Line: line, Fields: make(map[string]string, len(object))}
+	for name, raw := range object {
+		if string(raw) == "null" {
+			continue
+		}
+
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			record.Fields[name] = s
+		} else {
+			record.Fields[name] = string(raw)
+		}
+	}
+
+	return record
+}
+
+func eachJSON(r io.Reader, fn func(Record) error) error {
+	decoder := json.NewDecoder(r)
+	if _, err := decoder.Token(); err != nil {
+		return errors.Wrap(err, "expected a JSON array")
+	}
+
+	for line := 1; decoder.More(); line++ {
+		var object map[string]json.RawMessage
+		if err := decoder.Decode(&object); err != nil {
+			return errors.Wrapf(err, "object %v", line)
+		}
+
+		if err := fn(jsonRecord(line, object)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// longest JSON line read, as museum records can carry long descriptions
+const maxLineSize = 16 << 20
+
+func eachJSONLines(r io.Reader, fn func(Record) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), maxLineSize)
+
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		record := Record{Line: line}
+
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(scanner.Bytes(), &object); err != nil {
+			record.Err = err
+		} else {
+			record = jsonRecord(line, object)
+		}
+
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
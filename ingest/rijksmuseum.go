@@ -0,0 +1,113 @@
+package ingest
+
+import (
+	"encoding/json"
+	"strings"
+
+	"AT-BE/models"
+
+	"github.com/pkg/errors"
+)
+
+// Rijksmuseum reads responses saved from the Rijksmuseum collection API: search results
+// holding an artObjects array, single artObject details, or either as JSON lines. Details
+// carry the maker's nationality, the medium and the date, which search results leave out
+type Rijksmuseum struct{}
+
+func (Rijksmuseum) Source() models.Source {
+	return models.Source{Source_Name: "Rijksmuseum", Abriviation: "RIJKS"}
+}
+
+func (Rijksmuseum) Each(path string, fn func(Record) error) error {
+	return eachJSONItems(path, "artObjects", func(r Record) error {
+		// a details response wraps the object
+		if r.Get("artObject") != "" {
+			var object map[string]json.RawMessage
+			if err := nested(r, "artObject", &object); err != nil {
+				r = Record{Line: r.Line, Err: err}
+			} else {
+				r = jsonRecord(r.Line, object)
+			}
+		}
+
+		return fn(r)
+	})
+}
+
+type rijksMaker struct {
+	Name        string `json:"name"`
+	Nationality string `json:"nationality"`
+	Biography   string `json:"biography"`
+	DateOfBirth string `json:"dateOfBirth"`
+	DateOfDeath string `json:"dateOfDeath"`
+}
+
+type rijksImage struct {
+	URL string `json:"url"`
+}
+
+type rijksDating struct {
+	PresentingDate string `json:"presentingDate"`
+}
+
+func (Rijksmuseum) Parse(r Record) (ArtistRow, ArtworkRow, error) {
+	var makers []rijksMaker
+	var image rijksImage
+	var dating rijksDating
+	var places []string
+	for name, v := range map[string]interface{}{"principalMakers": &makers, "webImage": &image, "dating": &dating, "productionPlaces": &places} {
+		if err := nested(r, name, v); err != nil {
+			return ArtistRow{}, ArtworkRow{}, err
+		}
+	}
+
+	var maker rijksMaker
+	if len(makers) > 0 {
+		maker = makers[0]
+	}
+	if maker.Name == "" {
+		maker.Name = r.Get("principalOrFirstMaker")
+	}
+
+	// the API has no IDs for makers, their names are unique within the collection
+	artist := ArtistRow{External_ID: maker.Name, Artist: models.Artist{Name: maker.Name, Description: maker.Biography}}
+	if maker.Name == "" || strings.EqualFold(maker.Name, "anonymous") {
+		artist = unknownArtist
+	}
+
+	// written like the other sources' bios, e.g. "Dutch, 1606-07-15 - 1669-10-08"
+	var bio []string
+	if maker.Nationality != "" {
+		bio = append(bio, maker.Nationality)
+	}
+	if maker.DateOfBirth != "" || maker.DateOfDeath != "" {
+		bio = append(bio, maker.DateOfBirth+" - "+maker.DateOfDeath)
+	}
+
+	artwork := ArtworkRow{
+		External_ID:        r.Get("objectNumber"),
+		Artist_External_ID: artist.External_ID,
+		Artwork: models.Artwork{
+			Title:           r.Get("title"),
+			Nationality:     maker.Nationality,
+			Artist_Bio:      strings.Join(bio, ", "),
+			Desc:            r.Get("plaqueDescriptionEnglish", "description"),
+			Medium:          r.Get("physicalMedium"),
+			Date_of_Release: dating.PresentingDate,
+			Image:           image.URL,
+			Image_Small:     image.URL,
+		},
+	}
+	if len(places) > 0 {
+		artwork.Artwork.Nation = places[0]
+	}
+
+	switch {
+	case artwork.External_ID == "":
+		return artist, artwork, errors.New("objectNumber is required")
+	case artwork.Artwork.Title == "":
+		return artist, artwork, errors.New("title is required")
+	}
+
+	return artist, artwork, nil
+}
@@ -1,12 +1,20 @@
 package main
 
 import (
+	"AT-BE/apierror"
+	"AT-BE/broker"
+	"AT-BE/commands"
 	han "AT-BE/handlers"
 	m "AT-BE/middleware"
 	"AT-BE/models"
+	"AT-BE/rankings"
 	"AT-BE/utils"
+	"context"
 	"fmt"
+	"log"
+	"math/rand"
 	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -21,18 +29,65 @@ func main() {
 		panic(e)
 	}
 
+	router.Use(apierror.RequestID)
 	router.Use(m.CorsMiddleware(origins))
+	router.NoRoute(apierror.NoRoute)
 
-	fmt.Println("--migrating Users, ArtworkLikes, Curations, CurationLikes--")
-	db.AutoMigrate(&models.Users{}, &models.ArtworkLikes{}, &models.Curations{}, &models.CurationLikes{}, &models.CurationArtwork{})
+	if os.Getenv("broker") == "postgres" {
+		sqlDB, err := db.DB()
+		if err != nil {
+			panic(fmt.Errorf("failed to get sql.DB for broker %v", err))
+		}
+		han.UseBroker(broker.NewPostgresBroker(context.Background(), sqlDB))
+	}
+
+	fmt.Println("--migrating app tables and search documents--")
+	if err := models.Migrate(db); err != nil {
+		panic(fmt.Errorf("failed to migrate db %v", err))
+	}
+
+	// offline jobs such as `at-be palettes` run instead of the server
+	if len(os.Args) > 1 {
+		if err := commands.Run(db, os.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
 
+		return
+	}
+
+	similarity, err := han.LoadSimilarityIndex(db)
+	if err != nil {
+		panic(fmt.Errorf("failed to load image hashes %v", err))
+	}
+	fmt.Printf("--indexed %v image hashes--\n", similarity.Len())
+
+	// the ranking endpoints read what this job last computed
+	go rankings.Run(context.Background(), db, rankings.Interval())
+
+	// GET artwork/random picks from math/rand
+	rand.Seed(time.Now().UnixNano())
+
+	router.GET("artwork/random", han.GetRandomArtwork(db))
+	router.GET("artwork/daily", han.GetDailyArtwork(db))
 	router.GET("artwork/:id", han.GetArtwork(db))
+	router.GET("artwork/:id/similar", han.SimilarArtworks(db, similarity))
+	router.GET("artwork/:id/related", han.GetRelatedArtworks(db))
 	router.GET("artworks/", han.GetArtworks(db))
+	router.GET("artworks/batch", han.GetArtworkBatch(db))
+	router.GET("artworks/popular", han.GetPopularArtworks(db))
+	router.GET("artworks/trending", han.GetTrendingArtworks(db))
+	router.POST("artworks/batch", han.PostArtworkBatch(db))
+	router.GET("artists", han.GetArtists(db))
 	router.GET("artist/:id", han.GetArtist(db))
+	router.GET("eras", han.GetEras(db))
 	router.GET("era/:id", han.GetEra(db))
+	router.GET("sources", han.GetSources(db))
 	router.GET("source/:id", han.GetSource(db))
 
+	router.GET("search", han.Search(db))
 	router.GET("search/:term", han.Search(db))
+	// kept off search/ so that "suggest" can still be searched for
+	router.GET("suggest", han.Suggest(db))
 	router.GET("usernames", han.GetUsernames(db))
 
 	router.POST("sign-up", han.RegisterUser(db))
@@ -41,13 +96,44 @@ func main() {
 	router.POST("users", han.Users(db))
 	router.POST("logout", han.Logout(db))
 
+	router.GET("users/:id", m.OptionalAuthenticate, han.GetProfile(db))
+	router.POST("users/profile", m.Authenticate, han.UpdateProfileHandler(db))
+	router.POST("users/:id/follow", m.Authenticate, han.FollowUser(db))
+	router.POST("users/:id/unfollow", m.Authenticate, han.UnfollowUser(db))
+	router.GET("users/:id/followers", han.GetFollowers(db))
+	router.GET("users/:id/following", m.OptionalAuthenticate, han.GetFollowing(db))
+	router.POST("users/:id/block", m.Authenticate, han.BlockUser(db))
+	router.POST("users/:id/unblock", m.Authenticate, han.UnblockUser(db))
+	router.GET("blocks", m.Authenticate, han.GetBlocks(db))
+	router.POST("report", m.Authenticate, han.ReportHandler(db))
+
+	router.GET("admin/reports", m.Authenticate, m.RequireAdmin(db), han.GetReports(db))
+	router.POST("admin/reports/:id/resolve", m.Authenticate, m.RequireAdmin(db), han.DecideReport(db, models.ReportResolved))
+	router.POST("admin/reports/:id/dismiss", m.Authenticate, m.RequireAdmin(db), han.DecideReport(db, models.ReportDismissed))
+
 	router.POST("like", han.ArtworkLike(db))
 	router.POST("likes", han.CheckArtworkLikes(db))
 	router.GET("likedArtwork", m.Paginate, han.LikedArtworkHandler(db))
+	router.GET("feed", m.Authenticate, han.GetFeed(db))
+	router.GET("recommendations", m.Authenticate, han.GetRecommendations(db))
+
+	router.GET("events", m.Authenticate, han.StreamEvents(db))
+
+	router.GET("notifications", m.Authenticate, han.GetNotifications(db))
+	router.GET("notifications/unread", m.Authenticate, han.UnreadNotificationCount(db))
+	router.POST("notifications/:id/read", m.Authenticate, han.MarkNotificationRead(db))
+	router.POST("notifications/read-all", m.Authenticate, han.MarkAllNotificationsRead(db))
+	router.GET("notifications/preferences", m.Authenticate, han.GetNotificationPrefs(db))
+	router.POST("notifications/preferences", m.Authenticate, han.UpdateNotificationPref(db))
 
+	router.GET("curations/trending", m.OptionalAuthenticate, han.GetTrendingCurations(db))
 	router.POST("curation/new", han.NewCurationHandler(db))
+	router.POST("curation/:id/artworks", m.Authenticate, han.AddCurationArtwork(db))
 	router.POST("curation/delete", han.DeleteCurationHandler(db))
 	router.POST("curation/update", han.UpdateCurationNameHandler(db))
+	router.POST("curation/like", m.Authenticate, han.CurationLike(db))
+	router.POST("curation/:id/follow", m.Authenticate, han.FollowCuration(db))
+	router.POST("curation/:id/unfollow", m.Authenticate, han.UnfollowCuration(db))
 
 	d := fmt.Sprint(os.Getenv("HOST") + ":" + os.Getenv("PORT"))
 	router.Run(d)
@@ -1,10 +1,17 @@
 package middleware
 
 import (
+	"net/http"
+	"os"
 	"strconv"
 
+	"AT-BE/apierror"
+	"AT-BE/models"
+
+	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
+	"gorm.io/gorm"
 )
 
 func CorsMiddleware(origins string) gin.HandlerFunc {
@@ -50,3 +57,76 @@ func Paginate(c *gin.Context) {
 
 	c.Next()
 }
+
+// Parses the jwt cookie set by LoginUser into the ID of the user it was issued to
+func cookieUserID(c *gin.Context) (int, error) {
+	cookie, err := c.Cookie("jwt")
+	if err != nil {
+		return 0, errors.New("cookie could not be found for user")
+	}
+
+	keyFunc := func(t *jwt.Token) (interface{}, error) {
+		return []byte(os.Getenv("secretkey")), nil
+	}
+
+	token, err := jwt.ParseWithClaims(cookie, &jwt.StandardClaims{}, keyFunc)
+	if err != nil {
+		return 0, errors.Wrap(err, "unauthenticated user")
+	}
+
+	// has no Issuer attribute due to Claims being an interface, need to type cast
+	claim := token.Claims.(*jwt.StandardClaims)
+	authID, err := strconv.Atoi(claim.Issuer)
+	if err != nil {
+		return 0, errors.Wrap(err, "jwt issuer is not a user ID")
+	}
+
+	return authID, nil
+}
+
+// Parses the jwt cookie set by LoginUser and stores the user's ID under "authID".
+// Requests without a valid cookie are aborted with a 401
+func Authenticate(c *gin.Context) {
+	authID, err := cookieUserID(c)
+	if err != nil {
+		apierror.Respond(c, http.StatusUnauthorized, err.Error(), nil)
+
+		return
+	}
+
+	c.Set("authID", authID)
+
+	c.Next()
+}
+
+// Like Authenticate, but lets requests without a valid cookie through anonymously, leaving
+// "authID" unset so c.GetInt("authID") is 0. For public routes that tailor what they list
+// to the logged in user
+func OptionalAuthenticate(c *gin.Context) {
+	if authID, err := cookieUserID(c); err == nil {
+		c.Set("authID", authID)
+	}
+
+	c.Next()
+}
+
+// Only lets admins through, must run after Authenticate
+func RequireAdmin(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var user models.Users
+		result := db.Select("id", "admin").Where("id = ?", c.GetInt("authID")).Limit(1).Find(&user)
+		if result.Error != nil {
+			apierror.InternalError(c, result.Error)
+
+			return
+		}
+
+		if !user.Admin {
+			apierror.Respond(c, http.StatusForbidden, "admin access required", nil)
+
+			return
+		}
+
+		c.Next()
+	}
+}
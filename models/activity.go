@@ -0,0 +1,34 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// Verbs stored on Activity rows
+const (
+	VerbArtworkLiked         = "artwork_liked"
+	VerbCurationCreated      = "curation_created"
+	VerbCurationArtworkAdded = "curation_artwork_added"
+	VerbCurationLiked        = "curation_liked"
+)
+
+// Activity is a single event shown in the feed. Actor_ID is the user who did something,
+// Owner_ID is the owner of the curation it happened to, if any
+type Activity struct {
+	gorm.Model
+	Actor_ID    int    `json:"actor_id" gorm:"index"`
+	Verb        string `json:"verb"`
+	Artwork_ID  int    `json:"artwork_id"`
+	Curation_ID uint   `json:"curation_id" gorm:"index"`
+	Owner_ID    int    `json:"owner_id" gorm:"index"`
+}
+
+func (Activity) TableName() string {
+	return "activities"
+}
+
+type Feed struct {
+	Events []Activity `json:"events"`
+	// ID to pass as the cursor param for the next page, 0 when there are no more events
+	NextCursor uint `json:"next_cursor"`
+}
@@ -0,0 +1,237 @@
+package models
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+type ArtworkPage struct {
+	Artworks []Artwork `json:"artworks"`
+	// pass as the cursor param to get the next page, empty when there are no more artworks
+	NextCursor string `json:"next_cursor"`
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// SearchHit is a search result with its relevance and a highlighted snippet of the matching text
+type SearchHit struct {
+	Searches
+	Rank     float64 `json:"rank"`
+	Headline string  `json:"headline"`
+	// CIELAB difference between the colour param and the artwork's closest palette colour
+	Colour_Distance *float64 `json:"colour_distance,omitempty"`
+}
+
+type SearchResponse struct {
+	Results []SearchHit `json:"results"`
+	// keyed by the query param that filters on the facet
+	Facets   map[string][]FacetCount `json:"facets"`
+	Total    int64                   `json:"total"`
+	NextPage int                     `json:"page"`
+	// true when the full-text search found nothing and the results are close spellings instead
+	Fuzzy bool `json:"fuzzy"`
+	// language the term was stemmed for, see search.Language
+	Language string `json:"language"`
+}
+
+type Suggestion struct {
+	Value string  `json:"value"`
+	Kind  string  `json:"kind"`
+	Score float64 `json:"score"`
+}
+
+// ArtworkColours is one colour of an artwork's dominant palette, computed from Image_Small by
+// the palettes command. Weight is the share of the image the colour covers, and the L, A and B
+// columns are the colour in CIELAB so that searches can compare colours perceptually
+type ArtworkColours struct {
+	ID         uint      `json:"-" gorm:"primarykey"`
+	Artwork_ID int       `json:"artwork_id" gorm:"index"`
+	Hex        string    `json:"hex"`
+	Lab_L      float64   `json:"l"`
+	Lab_A      float64   `json:"a"`
+	Lab_B      float64   `json:"b"`
+	Weight     float64   `json:"weight"`
+	CreatedAt  time.Time `json:"-"`
+}
+
+func (ArtworkColours) TableName() string {
+	return "artwork_colours"
+}
+
+// ArtworkHashes holds the perceptual hashes of an artwork's Image_Small, computed by the hashes
+// command. The hashes are unsigned 64 bit values stored in signed bigint columns
+type ArtworkHashes struct {
+	ID         uint      `json:"-" gorm:"primarykey"`
+	Artwork_ID int       `json:"artwork_id" gorm:"uniqueIndex"`
+	P_Hash     int64     `json:"phash"`
+	D_Hash     int64     `json:"dhash"`
+	CreatedAt  time.Time `json:"-"`
+}
+
+func (ArtworkHashes) TableName() string {
+	return "artwork_hashes"
+}
+
+// SimilarArtwork is an artwork and how far its image hash is from the requested artwork's
+type SimilarArtwork struct {
+	Searches
+	Distance int `json:"distance"`
+}
+
+// RelatedArtworks groups other works related to an artwork. Each artwork appears in at most
+// one group
+type RelatedArtworks struct {
+	Artist []Searches `json:"artist"`
+	// works that appear alongside the artwork in public curations
+	Curations []Searches `json:"curations"`
+	Era       []Searches `json:"era"`
+	Source    []Searches `json:"source"`
+}
+
+// ArtistSummary is an artist in the artist listing along with how many works they have
+type ArtistSummary struct {
+	Artist
+	Work_Count int64 `json:"work_count"`
+}
+
+type ArtistList struct {
+	Artists []ArtistSummary `json:"artists"`
+	// artists per first letter of their name, A to Z with # for anything else. The letter
+	// filter is not applied, so every bucket stays visible while one is selected
+	Index    []FacetCount `json:"index"`
+	Total    int64        `json:"total"`
+	NextPage int          `json:"page"`
+}
+
+// SourceCount is a source and how many works of an artist or era it holds
+type SourceCount struct {
+	Source
+	Work_Count int64 `json:"work_count"`
+}
+
+// ArtistDetail is an artist with a page of their works and a summary of all of them. The
+// years come from each work's date of release and are nil when none has a year
+type ArtistDetail struct {
+	Artist
+	Works      []Artwork     `json:"works"`
+	Work_Count int64         `json:"work_count"`
+	First_Year *int          `json:"first_year"`
+	Last_Year  *int          `json:"last_year"`
+	Sources    []SourceCount `json:"sources"`
+	NextPage   int           `json:"page"`
+}
+
+// EraCount is an era and how many works by its artists are in the catalog
+type EraCount struct {
+	Era
+	Work_Count int64 `json:"work_count"`
+}
+
+// Collection summarises a group of works such as an era or a source: a page of the works,
+// how many there are, the artists with the most works in it and an image to represent it
+type Collection struct {
+	Works       []Artwork       `json:"works"`
+	Work_Count  int64           `json:"work_count"`
+	Top_Artists []ArtistSummary `json:"top_artists"`
+	// the most liked work with an image, nil when no work has one
+	Representative *Artwork `json:"representative"`
+	NextPage       int      `json:"page"`
+}
+
+type EraDetail struct {
+	Era
+	Collection
+}
+
+type SourceDetail struct {
+	Source
+	Collection
+}
+
+// ArtworkBatch holds the artworks found by a batch lookup in the order they were asked for,
+// and the IDs that do not exist
+type ArtworkBatch struct {
+	Artworks []Searches `json:"artworks"`
+	Missing  []int      `json:"missing"`
+}
+
+// DailyArtwork is the artwork of the day for Date (YYYY-MM-DD)
+type DailyArtwork struct {
+	Date    string   `json:"date"`
+	Artwork Searches `json:"artwork"`
+}
+
+// ArtworkBatchReq is the body of POST artworks/batch
+type ArtworkBatchReq struct {
+	IDs []int `json:"ids"`
+}
+
+func (b *ArtworkBatchReq) ProcessReq(req *http.Request) error {
+	data, ioErr := ioutil.ReadAll(req.Body)
+	if ioErr != nil {
+		return ioErr
+	}
+
+	if mErr := json.Unmarshal(data, &b); mErr != nil {
+		return mErr
+	}
+
+	return nil
+}
+
+// ArtworkNeighbours is the item-item recommendation model built by the recommendations command:
+// the artworks most often liked by the same users as Artwork_ID, scored by cosine similarity
+type ArtworkNeighbours struct {
+	ID           uint      `json:"-" gorm:"primarykey"`
+	Artwork_ID   int       `json:"artwork_id" gorm:"index"`
+	Neighbour_ID int       `json:"neighbour_id"`
+	Score        float64   `json:"score"`
+	CreatedAt    time.Time `json:"-"`
+}
+
+func (ArtworkNeighbours) TableName() string {
+	return "artwork_neighbours"
+}
+
+// Recommendation reasons
+const (
+	// liked by users who like the same works
+	ReasonLikedTogether = "liked_together"
+	// shares an artist, era or medium with liked works
+	ReasonSimilarWorks = "similar_works"
+	// popular, for users without likes
+	ReasonPopular = "popular"
+)
+
+// Recommendation is a recommended artwork with its score and the reason it was picked
+type Recommendation struct {
+	Searches
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason"`
+}
+
+// External ID kinds
+const (
+	ExternalArtwork = "artwork"
+	ExternalArtist  = "artist"
+)
+
+// ExternalIDs map the IDs a source uses for its artworks and artists to catalog rows, so the
+// ingest command can update a work it has loaded before instead of adding it again
+type ExternalIDs struct {
+	ID          uint      `json:"-" gorm:"primarykey"`
+	Kind        string    `json:"kind" gorm:"uniqueIndex:idx_external_ids"`
+	Source_ID   int       `json:"source_id" gorm:"uniqueIndex:idx_external_ids"`
+	External_ID string    `json:"external_id" gorm:"uniqueIndex:idx_external_ids"`
+	Item_ID     int       `json:"item_id"`
+	CreatedAt   time.Time `json:"-"`
+}
+
+func (ExternalIDs) TableName() string {
+	return "external_ids"
+}
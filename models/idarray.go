@@ -0,0 +1,69 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// IDArray is a list of IDs stored in a bigint[] column, so that queries can match an ID
+// against it with = any(column)
+type IDArray []uint
+
+func (IDArray) GormDataType() string {
+	return "bigint[]"
+}
+
+// Value writes the array literal, e.g. {1,2,3}
+func (a IDArray) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+
+	ids := make([]string, len(a))
+	for i, id := range a {
+		ids[i] = strconv.FormatUint(uint64(id), 10)
+	}
+
+	return "{" + strings.Join(ids, ",") + "}", nil
+}
+
+// Scan reads the text form of a bigint[], which is how postgres returns arrays
+func (a *IDArray) Scan(src interface{}) error {
+	var text string
+	switch v := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into IDArray", src)
+	}
+
+	text = strings.Trim(text, "{}")
+	if text == "" {
+		*a = IDArray{}
+		return nil
+	}
+
+	parts := strings.Split(text, ",")
+	ids := make(IDArray, 0, len(parts))
+	for _, part := range parts {
+		if part == "NULL" {
+			continue
+		}
+
+		id, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return fmt.Errorf("cannot scan %q into IDArray: %v", part, err)
+		}
+		ids = append(ids, uint(id))
+	}
+	*a = ids
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"AT-BE/search"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// tables created and updated by AutoMigrate. The artwork_migrate_* catalog tables and
+// searches are populated outside the app and are not migrated here
+var migratedModels = []interface{}{
+	&Users{}, &ArtworkLikes{}, &Curations{}, &CurationLikes{}, &CurationArtwork{},
+	&Follows{}, &Activity{}, &Notifications{}, &NotificationPrefs{},
+	&Blocks{}, &Reports{}, &ArtworkColours{}, &ArtworkHashes{}, &Rankings{},
+	&ArtworkNeighbours{}, &ExternalIDs{},
+}
+
+// SQL run before AutoMigrate, for changes it cannot make itself. Every statement must be safe
+// to run on each startup
+var preMigrations = []string{
+	// curations.artworks was created as a scalar bigint, which cannot hold the list of
+	// curation_artwork IDs. Existing values become one element arrays
+	`do $$ begin
+		if exists (select 1 from information_schema.columns
+			where table_name = 'curations' and column_name = 'artworks' and data_type = 'bigint') then
+			alter table curations alter column artworks type bigint[]
+				using case when artworks is null then null else array[artworks] end;
+		end if;
+	end $$`,
+}
+
+// SQL run after AutoMigrate, in order. Every statement must be safe to run on each startup
+var migrations = []string{
+	`create extension if not exists unaccent`,
+	`create extension if not exists pg_trgm`,
+	// unaccent is only stable, this wrapper pins the dictionary so it can be used in indexes
+	`create or replace function f_unaccent(text) returns text language sql immutable parallel safe strict as $$
+		select public.unaccent('public.unaccent'::regdictionary, $1)
+	$$`,
+}
+
+// Creates an accent folding text search configuration for each search language
+func languageMigrations() []string {
+	var statements []string
+	for _, language := range search.Languages {
+		dictionary := language + "_stem"
+		if language == search.DefaultLanguage {
+			dictionary = "simple"
+		}
+
+		statements = append(statements, fmt.Sprintf(`do $$ begin
+			if not exists (select 1 from pg_ts_config where cfgname = '%[1]v') then
+				create text search configuration %[1]v (copy = %[2]v);
+				alter text search configuration %[1]v alter mapping for hword, hword_part, word with unaccent, %[3]v;
+			end if;
+		end $$`, search.Config(language), language, dictionary))
+	}
+
+	return statements
+}
+
+// search_documents holds a weighted tsvector per artwork and search language, kept up to date
+// by triggers on the catalog tables: title (A), artist name (B), medium, culture and
+// nationality (C) and description (D). The description is read from the searches view so that
+// what is matched is the Description search results show
+func searchDocumentMigrations() []string {
+	configs := make([]string, len(search.Languages))
+	for i, language := range search.Languages {
+		configs[i] = "'" + search.Config(language) + "'"
+	}
+
+	return []string{
+		// documents from before search languages existed are rebuilt by the backfill below
+		`do $$ begin
+			if exists (select 1 from information_schema.tables where table_name = 'search_documents')
+				and not exists (select 1 from information_schema.columns where table_name = 'search_documents' and column_name = 'config') then
+				drop table search_documents;
+			end if;
+		end $$`,
+		`create table if not exists search_documents (
+			artwork_id bigint not null,
+			config regconfig not null,
+			document tsvector not null,
+			primary key (artwork_id, config)
+		)`,
+		`create index if not exists search_documents_document_idx on search_documents using gin (document)`,
+		`create or replace function refresh_search_documents(artwork_ids bigint[]) returns void language sql as $$
+			insert into search_documents (artwork_id, config, document)
+			select a.id, cfg,
+				setweight(to_tsvector(cfg, coalesce(a.title, '')), 'A') ||
+				setweight(to_tsvector(cfg, coalesce(ar.name, '')), 'B') ||
+				setweight(to_tsvector(cfg, concat_ws(' ', a.medium, a.culture, a.nationality)), 'C') ||
+				setweight(to_tsvector(cfg, coalesce(s."Description", '')), 'D')
+			from artwork_migrate_artwork as a
+			left join artwork_migrate_artist as ar on ar.id = a.artist_id
+			left join searches as s on s."ID" = a.id
+			cross join unnest(array[` + strings.Join(configs, ", ") + `]::regconfig[]) as cfg
+			where a.id = any(artwork_ids)
+			on conflict (artwork_id, config) do update set document = excluded.document
+		$$`,
+		`create or replace function search_documents_artwork_trigger() returns trigger language plpgsql as $$
+		begin
+			if tg_op = 'DELETE' then
+				delete from search_documents where artwork_id = old.id;
+				return old;
+			end if;
+
+			perform refresh_search_documents(array[new.id]::bigint[]);
+			return new;
+		end
+		$$`,
+		`drop trigger if exists search_documents_artwork on artwork_migrate_artwork`,
+		`create trigger search_documents_artwork after insert or update or delete on artwork_migrate_artwork
+			for each row execute function search_documents_artwork_trigger()`,
+		`create or replace function search_documents_artist_trigger() returns trigger language plpgsql as $$
+		begin
+			perform refresh_search_documents(array(select a.id from artwork_migrate_artwork as a where a.artist_id = new.id)::bigint[]);
+			return new;
+		end
+		$$`,
+		`drop trigger if exists search_documents_artist on artwork_migrate_artist`,
+		`create trigger search_documents_artist after update of name on artwork_migrate_artist
+			for each row execute function search_documents_artist_trigger()`,
+		// trigram indexes back the accent folded ilike prefix matches and the <% close spellings
+		// of the suggest endpoint and fuzzy search
+		`drop index if exists artwork_title_trgm_idx`,
+		`drop index if exists artist_name_trgm_idx`,
+		`create index if not exists artwork_title_unaccent_trgm_idx on artwork_migrate_artwork using gin (f_unaccent(title) gin_trgm_ops)`,
+		`create index if not exists artist_name_unaccent_trgm_idx on artwork_migrate_artist using gin (f_unaccent(name) gin_trgm_ops)`,
+		// documents built before the description came from searches are rebuilt once, the
+		// function's comment records that they have been
+		`do $$ begin
+			if coalesce(obj_description('refresh_search_documents(bigint[])'::regprocedure, 'pg_proc'), '') <> 'searches description' then
+				perform refresh_search_documents(array(select a.id from artwork_migrate_artwork as a)::bigint[]);
+				comment on function refresh_search_documents(bigint[]) is 'searches description';
+			end if;
+		end $$`,
+		// backfill artworks added before the triggers existed, or before a language was added
+		`select refresh_search_documents(array(
+			select a.id from artwork_migrate_artwork as a
+			where (select count(*) from search_documents as d where d.artwork_id = a.id) < ` + fmt.Sprint(len(configs)) + `
+		)::bigint[])`,
+	}
+}
+
+// Runs the pre-migrations, migrates the app's tables, then runs the SQL migrations
+func Migrate(db *gorm.DB) error {
+	for i, statement := range preMigrations {
+		if err := db.Exec(statement).Error; err != nil {
+			return errors.Wrapf(err, "pre-migration %v", i)
+		}
+	}
+
+	if err := db.AutoMigrate(migratedModels...); err != nil {
+		return errors.Wrap(err, "AutoMigrate")
+	}
+
+	statements := append([]string{}, migrations...)
+	statements = append(statements, languageMigrations()...)
+	statements = append(statements, searchDocumentMigrations()...)
+
+	for i, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return errors.Wrapf(err, "migration %v", i)
+		}
+	}
+
+	return nil
+}
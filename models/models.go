@@ -89,6 +89,13 @@ type Users struct {
 	Username string `json:"username" gorm:"unique"`
 	Email    string `json:"email"`
 	Password []byte `json:"-"`
+	// public profile fields, see Profile
+	Bio               string `json:"bio"`
+	Avatar_Artwork_ID int    `json:"avatar_artwork_id"`
+	Show_Likes        bool   `json:"show_likes"`
+	Private_Profile   bool   `json:"private_profile"`
+	// admins can work the moderation queue, only settable in the database
+	Admin bool `json:"-"`
 }
 
 func (Users) TableName() string {
@@ -100,9 +107,11 @@ func (Users) TableName() string {
 // together, along with the searches table and write the artwork objects to Curations.Artworks
 type Curations struct {
 	gorm.Model
-	User_ID  int    `json:"user_id"`
-	Name     string `json:"name"`
-	Artworks []uint `json:"curation_artwork_ids" gorm:"type:bigint"`
+	User_ID  int     `json:"user_id"`
+	Name     string  `json:"name"`
+	Artworks IDArray `json:"curation_artwork_ids"`
+	// Private curations are hidden from everyone but their owner and cannot be followed
+	Private bool `json:"private"`
 }
 
 func (Curations) TableName() string {
@@ -113,9 +122,25 @@ type NewCurationReq struct {
 	Name   string `json:"name"`
 	UserID int    `json:"userID"`
 	// The ID of the first artwork in the curation
+	ArtworkID int  `json:"artworkID"`
+	Private   bool `json:"private"`
+}
+
+// Body of POST curation/:id/artworks
+type AddCurationArtworkReq struct {
 	ArtworkID int `json:"artworkID"`
 }
 
+// Takes in request and processes the body for an instance of AddCurationArtworkReq
+func (a *AddCurationArtworkReq) ProcessReq(req *http.Request) error {
+	data, ioErr := ioutil.ReadAll(req.Body)
+	if ioErr != nil {
+		return ioErr
+	}
+
+	return json.Unmarshal(data, &a)
+}
+
 // Returns string of NewCurationReq
 func (n *NewCurationReq) ToString() string {
 	return fmt.Sprintf("Name: %v, UID: %v, AID: %v", n.Name, n.UserID, n.ArtworkID)
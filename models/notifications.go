@@ -0,0 +1,86 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Notification categories, users can mute any of these through NotificationPrefs. Only
+// categories something sends are listed, forks and collaboration invites will be added along
+// with those features
+const (
+	NotifyCurationLiked = "curation_liked"
+	NotifyNewFollower   = "new_follower"
+	NotifyNewCuration   = "new_curation"
+)
+
+var NotificationCategories = []string{
+	NotifyCurationLiked,
+	NotifyNewFollower,
+	NotifyNewCuration,
+}
+
+// Notifications are sent to User_ID when Actor_ID does something involving them.
+// Read_At stays nil until the user marks the notification as read
+type Notifications struct {
+	gorm.Model
+	User_ID     int        `json:"user_id" gorm:"index"`
+	Actor_ID    int        `json:"actor_id"`
+	Category    string     `json:"category"`
+	Curation_ID uint       `json:"curation_id"`
+	Artwork_ID  int        `json:"artwork_id"`
+	Read_At     *time.Time `json:"read_at"`
+}
+
+func (Notifications) TableName() string {
+	return "notifications"
+}
+
+// NotificationPrefs holds the categories a user has muted. A missing row means the
+// category is not muted
+type NotificationPrefs struct {
+	gorm.Model
+	User_ID  int    `json:"user_id" gorm:"uniqueIndex:idx_notification_prefs"`
+	Category string `json:"category" gorm:"uniqueIndex:idx_notification_prefs"`
+	Muted    bool   `json:"muted"`
+}
+
+func (NotificationPrefs) TableName() string {
+	return "notification_prefs"
+}
+
+type NotificationList struct {
+	Notifications []Notifications `json:"notifications"`
+	Unread        int64           `json:"unread"`
+	// ID to pass as the cursor param for the next page, 0 when there are no more notifications
+	NextCursor uint `json:"next_cursor"`
+}
+
+type UpdateNotificationPref struct {
+	Category string `json:"category"`
+	Muted    bool   `json:"muted"`
+}
+
+// Returns string of UpdateNotificationPref
+func (u *UpdateNotificationPref) ToString() string {
+	return fmt.Sprintf("Category: %v, Muted: %v", u.Category, u.Muted)
+}
+
+// Takes in request and processes the body for an instance of UpdateNotificationPref
+func (u *UpdateNotificationPref) ProcessReq(req *http.Request) error {
+	data, ioErr := ioutil.ReadAll(req.Body)
+	if ioErr != nil {
+		return ioErr
+	}
+
+	if mErr := json.Unmarshal(data, &u); mErr != nil {
+		return mErr
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// longest bio a user can save on their profile
+const MaxBioLength = 500
+
+// Profile is the public view of a user. Private profiles only show the username and join
+// date, and Liked_Count is left out unless the user has turned on Show_Likes
+type Profile struct {
+	ID          uint              `json:"id"`
+	Username    string            `json:"username"`
+	Joined      time.Time         `json:"joined"`
+	Bio         string            `json:"bio,omitempty"`
+	Avatar      *Searches         `json:"avatar,omitempty"`
+	Curations   []CurationSummary `json:"curations,omitempty"`
+	Liked_Count *int64            `json:"liked_count,omitempty"`
+	Followers   int64             `json:"followers"`
+	Following   int64             `json:"following"`
+	Private     bool              `json:"private"`
+}
+
+// UpdateProfile only changes the fields that are present in the request body
+type UpdateProfile struct {
+	Bio               *string `json:"bio"`
+	Avatar_Artwork_ID *int    `json:"avatar_artwork_id"`
+	Show_Likes        *bool   `json:"show_likes"`
+	Private_Profile   *bool   `json:"private_profile"`
+}
+
+// Returns string of UpdateProfile
+func (u *UpdateProfile) ToString() string {
+	str := func(v interface{}) string {
+		switch p := v.(type) {
+		case *string:
+			if p != nil {
+				return *p
+			}
+		case *int:
+			if p != nil {
+				return fmt.Sprint(*p)
+			}
+		case *bool:
+			if p != nil {
+				return fmt.Sprint(*p)
+			}
+		}
+		return "<unchanged>"
+	}
+
+	return fmt.Sprintf("Bio: %v, Avatar: %v, ShowLikes: %v, Private: %v",
+		str(u.Bio), str(u.Avatar_Artwork_ID), str(u.Show_Likes), str(u.Private_Profile))
+}
+
+// Takes in request and processes the body for an instance of UpdateProfile
+func (u *UpdateProfile) ProcessReq(req *http.Request) error {
+	data, ioErr := ioutil.ReadAll(req.Body)
+	if ioErr != nil {
+		return ioErr
+	}
+
+	if mErr := json.Unmarshal(data, &u); mErr != nil {
+		return mErr
+	}
+
+	return nil
+}
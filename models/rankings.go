@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Ranking kinds
+const (
+	RankArtworks  = "artworks"
+	RankCurations = "curations"
+)
+
+// Rankings hold the most liked artworks and curations for each period, recomputed by the
+// rankings job so that requests only read them. Period is "all" for all time likes, otherwise
+// a trending window such as "7d" whose Score decays with the age of each like
+type Rankings struct {
+	ID          uint      `json:"-" gorm:"primarykey"`
+	Kind        string    `json:"kind" gorm:"index:idx_rankings"`
+	Period      string    `json:"period" gorm:"index:idx_rankings"`
+	Rank        int       `json:"rank" gorm:"index:idx_rankings"`
+	Item_ID     int       `json:"item_id"`
+	Score       float64   `json:"score"`
+	Likes       int64     `json:"likes"`
+	Computed_At time.Time `json:"computed_at"`
+}
+
+func (Rankings) TableName() string {
+	return "rankings"
+}
+
+// RankedArtwork is an artwork along with its place in a ranking
+type RankedArtwork struct {
+	Searches
+	Rank  int     `json:"rank"`
+	Score float64 `json:"score"`
+	Likes int64   `json:"likes"`
+}
+
+// RankedCuration is a public curation along with its place in a ranking
+type RankedCuration struct {
+	CurationSummary
+	Rank  int     `json:"rank"`
+	Score float64 `json:"score"`
+	Likes int64   `json:"likes"`
+}
+
+// RankingList is the top of a ranking. Computed_At is when the rankings job last ran, and is
+// nil when the ranking is empty
+type RankingList struct {
+	Period      string           `json:"period"`
+	Computed_At *time.Time       `json:"computed_at"`
+	Artworks    []RankedArtwork  `json:"artworks,omitempty"`
+	Curations   []RankedCuration `json:"curations,omitempty"`
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// Follows records a user following either another user or a curation. Only one of
+// Followed_ID and Curation_ID is set on a row, the other is left at zero
+type Follows struct {
+	gorm.Model
+	Follower_ID int  `json:"follower_id" gorm:"uniqueIndex:idx_follows"`
+	Followed_ID int  `json:"followed_id" gorm:"uniqueIndex:idx_follows"`
+	Curation_ID uint `json:"curation_id" gorm:"uniqueIndex:idx_follows"`
+}
+
+func (Follows) TableName() string {
+	return "follows"
+}
+
+// PublicUser is the subset of Users that is safe to show to other users
+type PublicUser struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+}
+
+// CurationSummary is a curation without its artwork list, used when listing curations
+type CurationSummary struct {
+	ID      uint   `json:"id"`
+	User_ID int    `json:"user_id"`
+	Name    string `json:"name"`
+	Private bool   `json:"private"`
+}
+
+type FollowList struct {
+	Users     []PublicUser      `json:"users"`
+	Curations []CurationSummary `json:"curations,omitempty"`
+	// Count and NextPage are of the users. Followed curations are counted and paged apart,
+	// by the curation_page param
+	Count             int64 `json:"count"`
+	NextPage          int   `json:"page"`
+	Curation_Count    int64 `json:"curation_count,omitempty"`
+	Curation_NextPage int   `json:"curation_page,omitempty"`
+}
+
+// Blocks hide the blocked user's curations from the blocker and stop the blocked
+// user from liking the blocker's curations
+type Blocks struct {
+	gorm.Model
+	Blocker_ID int `json:"blocker_id" gorm:"uniqueIndex:idx_blocks"`
+	Blocked_ID int `json:"blocked_id" gorm:"uniqueIndex:idx_blocks"`
+}
+
+func (Blocks) TableName() string {
+	return "blocks"
+}
+
+// Report statuses, reports start open and are either resolved or dismissed by an admin
+const (
+	ReportOpen      = "open"
+	ReportResolved  = "resolved"
+	ReportDismissed = "dismissed"
+)
+
+// longest reason a user can give when reporting
+const MaxReportReasonLength = 1000
+
+// Reports are about either a user or a curation. Curation reports also record
+// the curation's owner in User_ID
+type Reports struct {
+	gorm.Model
+	Reporter_ID int    `json:"reporter_id"`
+	User_ID     int    `json:"user_id" gorm:"index"`
+	Curation_ID uint   `json:"curation_id"`
+	Reason      string `json:"reason"`
+	Status      string `json:"status" gorm:"index"`
+	Resolved_By int    `json:"resolved_by"`
+	Note        string `json:"note"`
+}
+
+func (Reports) TableName() string {
+	return "reports"
+}
+
+type NewReportReq struct {
+	UserID     int    `json:"userID"`
+	CurationID int    `json:"curationID"`
+	Reason     string `json:"reason"`
+}
+
+// Returns string of NewReportReq
+func (n *NewReportReq) ToString() string {
+	return fmt.Sprintf("UID: %v, CID: %v, Reason: %v", n.UserID, n.CurationID, n.Reason)
+}
+
+// Takes in request and processes the body for an instance of NewReportReq
+func (n *NewReportReq) ProcessReq(req *http.Request) error {
+	data, ioErr := ioutil.ReadAll(req.Body)
+	if ioErr != nil {
+		return ioErr
+	}
+
+	if mErr := json.Unmarshal(data, &n); mErr != nil {
+		return mErr
+	}
+
+	return nil
+}
+
+type ReportDecision struct {
+	Note string `json:"note"`
+}
+
+// Takes in request and processes the body for an instance of ReportDecision. An empty
+// body is allowed since the note is optional
+func (r *ReportDecision) ProcessReq(req *http.Request) error {
+	data, ioErr := ioutil.ReadAll(req.Body)
+	if ioErr != nil {
+		return ioErr
+	}
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	if mErr := json.Unmarshal(data, &r); mErr != nil {
+		return mErr
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+// Package rankings materialises the most liked and trending artworks and curations into the
+// rankings table, so that the ranking endpoints do not aggregate likes on every request
+package rankings
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"time"
+
+	"AT-BE/models"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// AllTime is the period of the all time ranking, which counts every like equally
+const AllTime = "all"
+
+// Windows are the trending periods. Only likes within a window count towards it
+var Windows = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+// a like counts half as much once it is a quarter of a trending window old
+const halfLifeFraction = 4
+
+// entries kept per kind and period
+const Size = 100
+
+// how often the rankings are refreshed when the rankinginterval env variable is not set
+const defaultInterval = 15 * time.Minute
+
+// arbitrary key for the advisory lock that stops instances refreshing at the same time
+const lockKey = 47001
+
+// the likes behind each kind of ranking. Curation likes only count while the curation is
+// public
+var likeSources = map[string]struct {
+	table  string
+	itemID string
+	join   string
+}{
+	models.RankArtworks: {"artwork_likes", "l.artwork_id", ""},
+	models.RankCurations: {"curation_likes", "l.curation_id",
+		"join curations as cur on cur.id = l.curation_id and cur.private = false and cur.deleted_at is null"},
+}
+
+// Periods lists the trending windows from shortest to longest
+func Periods() []string {
+	return []string{"24h", "7d", "30d"}
+}
+
+// Decay is the weight of a like that is age old in a trending window, halving every
+// window/halfLifeFraction
+func Decay(age time.Duration, window time.Duration) float64 {
+	halfLife := window / halfLifeFraction
+	if age < 0 {
+		age = 0
+	}
+
+	return math.Exp(-math.Ln2 * age.Seconds() / halfLife.Seconds())
+}
+
+// Replaces the ranking of kind over period, scoring likes as of now. A like's time is when its
+// row was last updated, as unliking and liking again update the same row
+func refresh(tx *gorm.DB, kind string, period string, now time.Time) error {
+	likes := likeSources[kind]
+
+	// args in placeholder order: the inserted values, then the score and the where clause
+	args := []interface{}{kind, period, now}
+	score := "count(*)"
+	where := "l.like = true and l.deleted_at is null"
+
+	if period != AllTime {
+		window := Windows[period]
+
+		// matches Decay
+		score = fmt.Sprintf("sum(exp(-ln(2) * extract(epoch from (cast(? as timestamptz) - l.updated_at)) / %v))",
+			(window / halfLifeFraction).Seconds())
+		where += " and l.updated_at > ?"
+		args = append(args, now, now.Add(-window))
+	}
+	args = append(args, Size)
+
+	statement := fmt.Sprintf(`insert into rankings (kind, period, rank, item_id, score, likes, computed_at)
+		select ?, ?, row_number() over (order by ranked.score desc, ranked.item_id), ranked.item_id, ranked.score, ranked.likes, ?
+		from (
+			select %v as item_id, %v as score, count(*) as likes
+			from %v as l %v
+			where %v
+			group by item_id
+			order by score desc, item_id
+			limit ?
+		) as ranked`, likes.itemID, score, likes.table, likes.join, where)
+
+	return tx.Exec(statement, args...).Error
+}
+
+// Refresh recomputes every ranking in one transaction, so readers see either the old or the
+// new rankings
+func Refresh(db *gorm.DB, now time.Time) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("select pg_advisory_xact_lock(?)", lockKey).Error; err != nil {
+			return errors.Wrap(err, "lock")
+		}
+
+		if err := tx.Exec("delete from rankings").Error; err != nil {
+			return errors.Wrap(err, "clear")
+		}
+
+		periods := map[string][]string{
+			models.RankArtworks:  append([]string{AllTime}, Periods()...),
+			models.RankCurations: Periods(),
+		}
+		for kind, kindPeriods := range periods {
+			for _, period := range kindPeriods {
+				if err := refresh(tx, kind, period, now); err != nil {
+					return errors.Wrapf(err, "%v %v", kind, period)
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// Interval reads how often to refresh the rankings from the rankinginterval env variable, a
+// duration such as "15m"
+func Interval() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv("rankinginterval"))
+	if err != nil || interval <= 0 {
+		return defaultInterval
+	}
+
+	return interval
+}
+
+// Run refreshes the rankings now and then every interval until ctx is done. Failures are
+// logged and retried on the next tick
+func Run(ctx context.Context, db *gorm.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := Refresh(db, time.Now()); err != nil {
+			log.Print(errors.Wrap(err, "unable to refresh rankings"))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
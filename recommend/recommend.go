@@ -0,0 +1,213 @@
+// Package recommend builds an item-item collaborative filtering model from artwork likes and
+// evaluates it offline. The model is a list of the most similar artworks for each artwork,
+// which the recommendations endpoint scores against a user's likes
+package recommend
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Likes maps a user ID to the artworks they like
+type Likes map[int][]int
+
+// Neighbour is an artwork similar to another along with their cosine similarity
+type Neighbour struct {
+	ID    int
+	Score float64
+}
+
+// Model holds the nearest neighbours of each artwork, most similar first
+type Model map[int][]Neighbour
+
+// Scored is a recommended artwork and the sum of its similarity to the liked artworks
+type Scored struct {
+	ID    int
+	Score float64
+}
+
+// MaxUserLikes caps the likes taken from one user when building a model, as each user adds
+// a pair for every two works they like
+const MaxUserLikes = 500
+
+// Build computes the cosine similarity between every two artworks liked by the same user,
+// treating each like as a 1 in a user by artwork matrix, and keeps the top neighbours of
+// each artwork
+func Build(likes Likes, neighbours int) Model {
+	counts := map[int]int{}
+	pairs := map[[2]int]int{}
+
+	for _, items := range likes {
+		items = unique(items)
+		if len(items) > MaxUserLikes {
+			items = items[:MaxUserLikes]
+		}
+
+		for i, a := range items {
+			counts[a]++
+			for _, b := range items[i+1:] {
+				if a < b {
+					pairs[[2]int{a, b}]++
+				} else {
+					pairs[[2]int{b, a}]++
+				}
+			}
+		}
+	}
+
+	model := Model{}
+	for pair, together := range pairs {
+		score := float64(together) / math.Sqrt(float64(counts[pair[0]]*counts[pair[1]]))
+		model[pair[0]] = append(model[pair[0]], Neighbour{pair[1], score})
+		model[pair[1]] = append(model[pair[1]], Neighbour{pair[0], score})
+	}
+
+	for id, list := range model {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Score != list[j].Score {
+				return list[i].Score > list[j].Score
+			}
+			return list[i].ID < list[j].ID
+		})
+		if len(list) > neighbours {
+			list = list[:neighbours]
+		}
+		model[id] = list
+	}
+
+	return model
+}
+
+// Recommend scores each neighbour of the liked artworks by its summed similarity to them and
+// returns the top k that are not already liked
+func (m Model) Recommend(liked []int, k int) []Scored {
+	seen := make(map[int]bool, len(liked))
+	for _, id := range liked {
+		seen[id] = true
+	}
+
+	scores := map[int]float64{}
+	for _, id := range liked {
+		for _, n := range m[id] {
+			if !seen[n.ID] {
+				scores[n.ID] += n.Score
+			}
+		}
+	}
+
+	ranked := make([]Scored, 0, len(scores))
+	for id, score := range scores {
+		ranked = append(ranked, Scored{id, score})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].ID < ranked[j].ID
+	})
+
+	if len(ranked) > k {
+		ranked = ranked[:k]
+	}
+
+	return ranked
+}
+
+// Evaluation is how well a model built from the training likes predicts the held out likes
+type Evaluation struct {
+	// users with enough likes to hold some out
+	Users int
+	// users given at least one recommendation
+	Covered   int
+	Precision float64
+	Recall    float64
+}
+
+// Split holds out a fraction of the likes of each user with at least two, keeping at least one
+// like of theirs for training. Users with one like are only used for training
+func Split(likes Likes, holdout float64, rng *rand.Rand) (Likes, Likes) {
+	train, test := Likes{}, Likes{}
+
+	users := make([]int, 0, len(likes))
+	for user := range likes {
+		users = append(users, user)
+	}
+	// map order is random, the split should only depend on rng
+	sort.Ints(users)
+
+	for _, user := range users {
+		items := unique(likes[user])
+		if len(items) < 2 {
+			train[user] = items
+			continue
+		}
+
+		rng.Shuffle(len(items), func(i, j int) { items[i], items[j] = items[j], items[i] })
+
+		n := int(math.Round(float64(len(items)) * holdout))
+		if n < 1 {
+			n = 1
+		}
+		if n > len(items)-1 {
+			n = len(items) - 1
+		}
+
+		test[user] = items[:n]
+		train[user] = items[n:]
+	}
+
+	return train, test
+}
+
+// Evaluate builds a model from train and reports precision@k and recall@k over the users in
+// test, averaged across users
+func Evaluate(train Likes, test Likes, k int, neighbours int) Evaluation {
+	model := Build(train, neighbours)
+
+	var eval Evaluation
+	for user, heldOut := range test {
+		eval.Users++
+
+		recs := model.Recommend(train[user], k)
+		if len(recs) > 0 {
+			eval.Covered++
+		}
+
+		want := make(map[int]bool, len(heldOut))
+		for _, id := range heldOut {
+			want[id] = true
+		}
+
+		hits := 0
+		for _, rec := range recs {
+			if want[rec.ID] {
+				hits++
+			}
+		}
+
+		eval.Precision += float64(hits) / float64(k)
+		eval.Recall += float64(hits) / float64(len(heldOut))
+	}
+
+	if eval.Users > 0 {
+		eval.Precision /= float64(eval.Users)
+		eval.Recall /= float64(eval.Users)
+	}
+
+	return eval
+}
+
+// Returns ids without repeats, keeping the first of each
+func unique(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	out := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+
+	return out
+}
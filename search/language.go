@@ -0,0 +1,54 @@
+package search
+
+import (
+	"strings"
+)
+
+// text search configuration used when the request does not ask for a supported language
+const DefaultLanguage = "simple"
+
+// Languages with a text search configuration. Each is created by models.Migrate as
+// at_<language>, copying the postgres configuration and folding accents with unaccent
+var Languages = []string{"simple", "english", "dutch", "french", "german", "italian", "spanish"}
+
+// ISO 639-1 codes accepted in place of the language name
+var languageCodes = map[string]string{
+	"en": "english",
+	"nl": "dutch",
+	"fr": "french",
+	"de": "german",
+	"it": "italian",
+	"es": "spanish",
+}
+
+// Config returns the text search configuration name for a language
+func Config(language string) string {
+	return "at_" + language
+}
+
+// Language picks the search language from a lang param or an Accept-Language header, trying
+// the lang param first and then each header entry in order. Region subtags and quality values
+// are ignored (en-GB;q=0.8 is english) and DefaultLanguage is returned when nothing is supported
+func Language(param string, acceptLanguage string) string {
+	candidates := []string{param}
+	for _, entry := range strings.Split(acceptLanguage, ",") {
+		candidates = append(candidates, strings.SplitN(entry, ";", 2)[0])
+	}
+
+	for _, candidate := range candidates {
+		candidate = strings.ToLower(strings.TrimSpace(candidate))
+		candidate = strings.SplitN(candidate, "-", 2)[0]
+
+		if name, ok := languageCodes[candidate]; ok {
+			return name
+		}
+
+		for _, language := range Languages {
+			if candidate == language {
+				return language
+			}
+		}
+	}
+
+	return DefaultLanguage
+}
@@ -0,0 +1,276 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// most clauses accepted in a single query
+const MaxClauses = 20
+
+// Fields that can be used as field:value in a query. date takes a year or a range of years
+var Fields = map[string]bool{
+	"artist":      true,
+	"title":       true,
+	"medium":      true,
+	"culture":     true,
+	"nationality": true,
+	"gender":      true,
+	"source":      true,
+	"era":         true,
+	"date":        true,
+}
+
+// Clause is one part of a parsed query: Text, Match or Range
+type Clause interface {
+	Position() int
+	IsNegated() bool
+}
+
+// Text is free text matched against the search documents. Phrase is set for quoted text
+type Text struct {
+	Value   string
+	Phrase  bool
+	Negated bool
+	Pos     int
+}
+
+// Match is a field:value clause such as medium:oil
+type Match struct {
+	Field   string
+	Value   string
+	Negated bool
+	Pos     int
+}
+
+// Range is a date clause such as date:1870..1890. From or To is nil for an open ended range
+type Range struct {
+	Field   string
+	From    *int
+	To      *int
+	Negated bool
+	Pos     int
+}
+
+func (t Text) Position() int    { return t.Pos }
+func (t Text) IsNegated() bool  { return t.Negated }
+func (m Match) Position() int   { return m.Pos }
+func (m Match) IsNegated() bool { return m.Negated }
+func (r Range) Position() int   { return r.Pos }
+func (r Range) IsNegated() bool { return r.Negated }
+
+type Query struct {
+	Clauses []Clause
+}
+
+// SyntaxError points at the token of the query that could not be parsed. Pos is a byte offset
+type SyntaxError struct {
+	Pos     int
+	Token   string
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%v at position %v: %q", e.Message, e.Pos, e.Token)
+}
+
+// Parses queries such as `artist:"Monet" medium:oil date:1870..1890 -water`. Clauses are
+// separated by whitespace, prefixed with - to negate them, and values containing spaces are
+// quoted. Text that is not a field:value pair is matched as free text
+func Parse(input string) (*Query, error) {
+	p := parser{input: input}
+	q := &Query{}
+
+	for {
+		p.skipSpace()
+		if p.done() {
+			break
+		}
+
+		clause, err := p.clause()
+		if err != nil {
+			return nil, err
+		}
+
+		q.Clauses = append(q.Clauses, clause)
+		if len(q.Clauses) > MaxClauses {
+			return nil, &SyntaxError{Pos: clause.Position(), Token: p.input[clause.Position():p.pos], Message: "too many clauses"}
+		}
+	}
+
+	if len(q.Clauses) == 0 {
+		return nil, &SyntaxError{Pos: 0, Token: input, Message: "query is empty"}
+	}
+
+	return q, nil
+}
+
+// Joins the free text that is not negated, used for ranking and highlighting
+func (q *Query) Text() string {
+	var words []string
+	for _, clause := range q.Clauses {
+		if t, ok := clause.(Text); ok && !t.Negated {
+			words = append(words, t.Value)
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// the query is scanned byte by byte, so only ASCII is treated as whitespace or field names
+// to avoid splitting multi-byte characters
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+func isLetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) skipSpace() {
+	for !p.done() && isSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+// Reads up to the next whitespace, failing on quotes in the middle of a word
+func (p *parser) word() (string, error) {
+	start := p.pos
+	for !p.done() && !isSpace(p.input[p.pos]) {
+		if p.input[p.pos] == '"' {
+			return "", &SyntaxError{Pos: p.pos, Token: p.input[start : p.pos+1], Message: "unexpected quote"}
+		}
+		p.pos++
+	}
+
+	return p.input[start:p.pos], nil
+}
+
+// Reads a quoted value, p.pos must be on the opening quote
+func (p *parser) quoted() (string, error) {
+	start := p.pos
+	end := strings.IndexByte(p.input[start+1:], '"')
+	if end < 0 {
+		return "", &SyntaxError{Pos: start, Token: p.input[start:], Message: "unterminated quote"}
+	}
+
+	value := p.input[start+1 : start+1+end]
+	p.pos = start + end + 2
+
+	if !p.done() && !isSpace(p.input[p.pos]) {
+		return "", &SyntaxError{Pos: p.pos, Token: p.input[start : p.pos+1], Message: "expected a space after the closing quote"}
+	}
+
+	if strings.TrimSpace(value) == "" {
+		return "", &SyntaxError{Pos: start, Token: p.input[start:p.pos], Message: "quoted value is empty"}
+	}
+
+	return value, nil
+}
+
+func (p *parser) clause() (Clause, error) {
+	start := p.pos
+	negated := false
+
+	if p.input[p.pos] == '-' {
+		negated = true
+		p.pos++
+		if p.done() || isSpace(p.input[p.pos]) {
+			return nil, &SyntaxError{Pos: start, Token: "-", Message: "nothing to negate"}
+		}
+	}
+
+	if p.input[p.pos] == '"' {
+		value, err := p.quoted()
+		if err != nil {
+			return nil, err
+		}
+
+		return Text{Value: value, Phrase: true, Negated: negated, Pos: start}, nil
+	}
+
+	// a field name is a run of letters followed by a colon
+	nameEnd := p.pos
+	for nameEnd < len(p.input) && isLetter(p.input[nameEnd]) {
+		nameEnd++
+	}
+
+	if nameEnd == p.pos || nameEnd >= len(p.input) || p.input[nameEnd] != ':' {
+		value, err := p.word()
+		if err != nil {
+			return nil, err
+		}
+
+		return Text{Value: value, Negated: negated, Pos: start}, nil
+	}
+
+	field := strings.ToLower(p.input[p.pos:nameEnd])
+	if !Fields[field] {
+		return nil, &SyntaxError{Pos: p.pos, Token: p.input[p.pos : nameEnd+1], Message: "unknown field"}
+	}
+	p.pos = nameEnd + 1
+
+	var value string
+	var err error
+	if !p.done() && p.input[p.pos] == '"' {
+		value, err = p.quoted()
+	} else {
+		value, err = p.word()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if value == "" {
+		return nil, &SyntaxError{Pos: start, Token: p.input[start:p.pos], Message: "missing value for " + field}
+	}
+
+	if field == "date" {
+		return parseRange(field, value, negated, start, p.input[start:p.pos])
+	}
+
+	return Match{Field: field, Value: value, Negated: negated, Pos: start}, nil
+}
+
+// Parses a year (1870) or a range of years (1870..1890, 1870.., ..1890)
+func parseRange(field string, value string, negated bool, pos int, token string) (Clause, error) {
+	from, to := value, value
+	if i := strings.Index(value, ".."); i >= 0 {
+		from, to = value[:i], value[i+2:]
+		if from == "" && to == "" {
+			return nil, &SyntaxError{Pos: pos, Token: token, Message: "range needs a start or an end"}
+		}
+	}
+
+	r := Range{Field: field, Negated: negated, Pos: pos}
+	for _, bound := range []struct {
+		text string
+		dest **int
+	}{{from, &r.From}, {to, &r.To}} {
+		if bound.text == "" {
+			continue
+		}
+
+		year, err := strconv.Atoi(bound.text)
+		if err != nil {
+			return nil, &SyntaxError{Pos: pos, Token: token, Message: "expected a year"}
+		}
+		*bound.dest = &year
+	}
+
+	if r.From != nil && r.To != nil && *r.From > *r.To {
+		return nil, &SyntaxError{Pos: pos, Token: token, Message: "range start is after its end"}
+	}
+
+	return r, nil
+}
@@ -0,0 +1,85 @@
+package search
+
+import (
+	"strings"
+)
+
+// first four digit year in the searches DOR text
+const releaseYear = "cast(substring(s.\"DOR\" from '[0-9]{4}') as int)"
+
+// columns matched by each field. Aliases are those used by the search handler: s for searches,
+// d for search_documents, a for artwork_migrate_artwork and ar for artwork_migrate_artist
+var columns = map[string]string{
+	"artist":      "s.\"Artist_Name\"",
+	"title":       "s.\"Title\"",
+	"medium":      "a.medium",
+	"culture":     "a.culture",
+	"nationality": "a.nationality",
+	"gender":      "a.gender",
+	"source":      "concat_ws(' ', s.\"Abb\", s.\"Source\")",
+	"era":         "ar.era",
+	"date":        releaseYear,
+}
+
+// fields compared as a whole rather than matching anywhere in the column
+var exactFields = map[string]bool{
+	"gender": true,
+	"era":    true,
+}
+
+var likeEscaper = strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_")
+
+// EscapeLike escapes the LIKE wildcards in s, along with the backslash that escapes them, so
+// that s only matches itself in a like or ilike pattern
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// Condition translates the query into a where clause and its args, parsing free text with the
+// text search configuration named by config. Values are only ever passed as args, never written
+// into the SQL, and field matches ignore accents
+func (q *Query) Condition(config string) (string, []interface{}) {
+	var parts []string
+	var args []interface{}
+
+	for _, clause := range q.Clauses {
+		var sql string
+
+		switch c := clause.(type) {
+		case Text:
+			if c.Phrase {
+				sql = "d.document @@ phraseto_tsquery(cast(? as regconfig), ?)"
+			} else {
+				sql = "d.document @@ plainto_tsquery(cast(? as regconfig), ?)"
+			}
+			args = append(args, config, c.Value)
+		case Match:
+			value := "%" + likeEscaper.Replace(c.Value) + "%"
+			if exactFields[c.Field] {
+				value = likeEscaper.Replace(c.Value)
+			}
+			sql = "f_unaccent(" + columns[c.Field] + ") ilike f_unaccent(?)"
+			args = append(args, value)
+		case Range:
+			var bounds []string
+			if c.From != nil {
+				bounds = append(bounds, columns[c.Field]+" >= ?")
+				args = append(args, *c.From)
+			}
+			if c.To != nil {
+				bounds = append(bounds, columns[c.Field]+" <= ?")
+				args = append(args, *c.To)
+			}
+			sql = strings.Join(bounds, " and ")
+		}
+
+		// columns can be null, which should count as not matching rather than unknown
+		if clause.IsNegated() {
+			sql = "not coalesce(" + sql + ", false)"
+		}
+
+		parts = append(parts, "("+sql+")")
+	}
+
+	return strings.Join(parts, " and "), args
+}
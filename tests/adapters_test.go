@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"AT-BE/ingest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type adapted struct {
+	artist  ingest.ArtistRow
+	artwork ingest.ArtworkRow
+	err     error
+}
+
+// reads and parses every record of a fixture dump with adapter
+func adaptDump(t *testing.T, adapter ingest.SourceAdapter, name string) []adapted {
+	var rows []adapted
+	err := adapter.Each(filepath.Join("testdata", "ingest", name), func(r ingest.Record) error {
+		row := adapted{err: r.Err}
+		if r.Err == nil {
+			row.artist, row.artwork, row.err = adapter.Parse(r)
+		}
+		rows = append(rows, row)
+
+		return nil
+	})
+	if err != nil {
+		t.Errorf("[ERROR] Unable to read %v: %s", name, err)
+	}
+
+	return rows
+}
+
+func TestAdapterSources(t *testing.T) {
+	for _, name := range ingest.AdapterNames() {
+		source := ingest.Adapters[name].Source()
+		assert.NotEmpty(t, source.Source_Name, name)
+		assert.NotEmpty(t, source.Abriviation, name)
+	}
+}
+
+func TestMetAdapter(t *testing.T) {
+	rows := adaptDump(t, ingest.Met{}, "met.csv")
+	if !assert.Len(t, rows, 4) {
+		return
+	}
+
+	gogh := rows[0]
+	assert.Nil(t, gogh.err)
+	assert.Equal(t, "436535", gogh.artwork.External_ID)
+	assert.Equal(t, "Wheat Field with Cypresses", gogh.artwork.Artwork.Title)
+	assert.Equal(t, "Dutch", gogh.artwork.Artwork.Nationality)
+	assert.Equal(t, "1889", gogh.artwork.Artwork.Date_of_Release)
+	assert.Equal(t, "Vincent van Gogh", gogh.artist.Artist.Name)
+	assert.Equal(t, "http://vocab.getty.edu/page/ulan/500115588", gogh.artist.External_ID)
+	assert.Equal(t, gogh.artist.External_ID, gogh.artwork.Artist_External_ID)
+
+	// only the first of several artists is kept, identified by Wikidata without a ULAN record
+	cassatt := rows[1]
+	assert.Equal(t, "Mary Cassatt", cassatt.artist.Artist.Name)
+	assert.Equal(t, "Female", cassatt.artist.Artist.Gender)
+	assert.Equal(t, "https://www.wikidata.org/wiki/Q173223", cassatt.artist.External_ID)
+
+	// uncredited works are given the unknown artist
+	hippo := rows[2]
+	assert.Nil(t, hippo.err)
+	assert.Equal(t, "Unknown", hippo.artist.Artist.Name)
+	assert.Equal(t, "Egypt", hippo.artwork.Artwork.Nation)
+
+	assert.EqualError(t, rows[3].err, "Object ID is required")
+}
+
+func TestRijksmuseumAdapter(t *testing.T) {
+	rows := adaptDump(t, ingest.Rijksmuseum{}, "rijksmuseum.json")
+	if !assert.Len(t, rows, 2) {
+		return
+	}
+
+	watch := rows[0]
+	assert.Nil(t, watch.err)
+	assert.Equal(t, "SK-C-5", watch.artwork.External_ID)
+	assert.Equal(t, "Rembrandt van Rijn", watch.artist.External_ID)
+	assert.Equal(t, "Amsterdam", watch.artwork.Artwork.Nation)
+	assert.Equal(t, "https://lh3.googleusercontent.com/night-watch=s0", watch.artwork.Artwork.Image_Small)
+
+	assert.Equal(t, "Unknown", rows[1].artist.Artist.Name)
+	assert.Empty(t, rows[1].artwork.Artwork.Image)
+
+	details := adaptDump(t, ingest.Rijksmuseum{}, "rijksmuseum.jsonl")
+	if !assert.Len(t, details, 2) {
+		return
+	}
+
+	milkmaid := details[0]
+	assert.Nil(t, milkmaid.err)
+	assert.Equal(t, "SK-A-2344", milkmaid.artwork.External_ID)
+	assert.Equal(t, "Johannes Vermeer", milkmaid.artist.Artist.Name)
+	assert.Equal(t, "Dutch", milkmaid.artwork.Artwork.Nationality)
+	assert.Equal(t, "Dutch, 1632-10-31 - 1675-12-15", milkmaid.artwork.Artwork.Artist_Bio)
+	assert.Equal(t, "c. 1660", milkmaid.artwork.Artwork.Date_of_Release)
+	assert.Equal(t, "oil on canvas", milkmaid.artwork.Artwork.Medium)
+	assert.Equal(t, "Vermeer depicts a kitchen maid.", milkmaid.artwork.Artwork.Desc)
+
+	assert.NotNil(t, details[1].err)
+}
+
+func TestAICAdapter(t *testing.T) {
+	rows := adaptDump(t, ingest.AIC{}, "aic.json")
+	if !assert.Len(t, rows, 2) {
+		return
+	}
+
+	jatte := rows[0]
+	assert.Nil(t, jatte.err)
+	assert.Equal(t, "27992", jatte.artwork.External_ID)
+	assert.Equal(t, "40610", jatte.artist.External_ID)
+	assert.Equal(t, "Georges Seurat", jatte.artist.Artist.Name)
+	assert.Equal(t, "French", jatte.artwork.Artwork.Nationality)
+	assert.Equal(t, "French, 1859-1891", jatte.artwork.Artwork.Artist_Bio)
+	assert.Equal(t, "In his best-known & largest painting, Seurat depicted people relaxing.", jatte.artwork.Artwork.Desc)
+	assert.Equal(t, "https://www.artic.edu/iiif/2/2d484387-2509-5e8e-2c43-22f9981972eb/full/843,/0/default.jpg", jatte.artwork.Artwork.Image)
+	assert.Contains(t, jatte.artwork.Artwork.Image_Small, "/full/200,/0/")
+
+	textile := rows[1]
+	assert.Nil(t, textile.err)
+	assert.Equal(t, "Unknown", textile.artist.Artist.Name)
+	assert.Empty(t, textile.artwork.Artwork.Image)
+
+	// the data dump has one artwork per file
+	single := adaptDump(t, ingest.AIC{}, "aic-111628.json")
+	if assert.Len(t, single, 1) {
+		assert.Equal(t, "Nighthawks", single[0].artwork.Artwork.Title)
+		assert.Equal(t, "American", single[0].artwork.Artwork.Nationality)
+	}
+}
@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"AT-BE/broker"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryBroker(t *testing.T) {
+	b := broker.NewMemoryBroker()
+
+	userEvents, unsubscribeUser := b.Subscribe(broker.UserTopic(16))
+	curationEvents, unsubscribeCuration := b.Subscribe(broker.UserTopic(16), broker.CurationTopic(3))
+
+	if err := b.Publish(broker.CurationTopic(3), broker.Event{Type: "curation_renamed"}); err != nil {
+		t.Error(err)
+	}
+	if err := b.Publish(broker.UserTopic(16), broker.Event{Type: "curation_liked"}); err != nil {
+		t.Error(err)
+	}
+
+	select {
+	case e := <-userEvents:
+		assert.Equal(t, "curation_liked", e.Type)
+	case <-time.After(time.Second):
+		t.Error("[Error] user subscriber did not receive event")
+	}
+
+	received := []string{}
+	for i := 0; i < 2; i++ {
+		select {
+		case e := <-curationEvents:
+			received = append(received, e.Type)
+		case <-time.After(time.Second):
+			t.Error("[Error] curation subscriber did not receive event")
+		}
+	}
+	assert.Equal(t, []string{"curation_renamed", "curation_liked"}, received)
+
+	// unsubscribing closes the channel and later publishes are not delivered
+	unsubscribeUser()
+	_, open := <-userEvents
+	assert.False(t, open)
+
+	unsubscribeCuration()
+	unsubscribeCuration()
+	assert.Nil(t, b.Publish(broker.UserTopic(16), broker.Event{Type: "curation_liked"}))
+}
@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"AT-BE/imaging"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHex(t *testing.T) {
+	c, err := imaging.ParseHex("#1e3a8a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, color.RGBA{R: 0x1e, G: 0x3a, B: 0x8a, A: 255}, c)
+
+	c, err = imaging.ParseHex("FFF")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, c)
+	assert.Equal(t, "#ffffff", imaging.Hex(c))
+
+	for _, bad := range []string{"", "#12345", "blue", "#12345g"} {
+		_, err := imaging.ParseHex(bad)
+		assert.NotNil(t, err, bad)
+	}
+}
+
+func TestToLab(t *testing.T) {
+	cases := []struct {
+		colour color.RGBA
+		lab    imaging.Lab
+	}{
+		{color.RGBA{255, 255, 255, 255}, imaging.Lab{L: 100, A: 0, B: 0}},
+		{color.RGBA{0, 0, 0, 255}, imaging.Lab{L: 0, A: 0, B: 0}},
+		{color.RGBA{255, 0, 0, 255}, imaging.Lab{L: 53.24, A: 80.09, B: 67.20}},
+		{color.RGBA{0, 0, 255, 255}, imaging.Lab{L: 32.30, A: 79.19, B: -107.86}},
+	}
+
+	for _, c := range cases {
+		lab := imaging.ToLab(c.colour)
+		assert.True(t, lab.Distance(c.lab) < 0.1, "%v converted to %v", c.colour, lab)
+	}
+
+	// navy is closer to blue than to red
+	navy := imaging.ToLab(color.RGBA{0, 0, 128, 255})
+	assert.True(t, navy.Distance(cases[3].lab) < navy.Distance(cases[2].lab))
+}
+
+func TestPalette(t *testing.T) {
+	blue := color.RGBA{30, 58, 138, 255}
+	cream := color.RGBA{240, 230, 200, 255}
+
+	// the left three quarters are blue with slight noise, the rest cream
+	img := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 200; x++ {
+			if x < 150 {
+				img.Set(x, y, color.RGBA{blue.R + uint8((x+y)%3), blue.G, blue.B, 255})
+			} else {
+				img.Set(x, y, cream)
+			}
+		}
+	}
+
+	palette := imaging.Palette(img, 5)
+	if len(palette) < 2 {
+		t.Fatalf("expected at least two colours, got %v", palette)
+	}
+
+	assert.True(t, math.Abs(palette[0].Weight-0.75) < 0.05, "blue weight %v", palette[0].Weight)
+	assert.True(t, palette[0].Lab.Distance(imaging.ToLab(blue)) < 3, "first colour %v", imaging.Hex(palette[0].Colour))
+
+	var total float64
+	creamFound := false
+	for _, swatch := range palette {
+		total += swatch.Weight
+		if swatch.Lab.Distance(imaging.ToLab(cream)) < 3 {
+			creamFound = true
+		}
+	}
+	assert.True(t, creamFound)
+	assert.True(t, math.Abs(total-1) < 1e-9)
+
+	// fully transparent images have no palette
+	assert.Empty(t, imaging.Palette(image.NewNRGBA(image.Rect(0, 0, 10, 10)), 5))
+}
+
+// draws a soft diagonal pattern, scaled to the image size so that sizes can be compared
+func pattern(w int, h int, invert bool) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			fx, fy := float64(x)/float64(w), float64(y)/float64(h)
+			v := uint8(127 + 120*math.Sin(6*fx+3*fy*fy))
+			if invert {
+				v = 255 - v
+			}
+			img.Set(x, y, color.RGBA{v, v / 2, 255 - v, 255})
+		}
+	}
+
+	return img
+}
+
+func TestPerceptualHash(t *testing.T) {
+	original := imaging.PerceptualHash(pattern(200, 150, false))
+	resized := imaging.PerceptualHash(pattern(90, 67, false))
+	inverted := imaging.PerceptualHash(pattern(200, 150, true))
+
+	assert.Equal(t, original, imaging.PerceptualHash(pattern(200, 150, false)))
+	assert.True(t, original.Distance(resized) <= 8, "resized copy is %v away", original.Distance(resized))
+	assert.True(t, original.Distance(inverted) >= 40, "inverted image is %v away", original.Distance(inverted))
+	assert.Equal(t, imaging.MaxHashDistance, imaging.Hash{}.Distance(imaging.Hash{P: ^uint64(0), D: ^uint64(0)}))
+}
+
+func TestBKTree(t *testing.T) {
+	tree := imaging.NewBKTree()
+	assert.Empty(t, tree.Search(imaging.Hash{}, 10, 5))
+
+	// deterministic pseudo random hashes, checked against a linear scan
+	hashes := make(map[int]imaging.Hash)
+	state := uint64(88172645463325252)
+	next := func() uint64 {
+		state ^= state << 13
+		state ^= state >> 7
+		state ^= state << 17
+		return state
+	}
+	for id := 1; id <= 500; id++ {
+		hashes[id] = imaging.Hash{P: next(), D: next()}
+		tree.Add(id, hashes[id])
+	}
+	tree.Add(1, imaging.Hash{})
+
+	assert.Equal(t, 500, tree.Len())
+	h, ok := tree.Hash(1)
+	assert.True(t, ok)
+	assert.Equal(t, hashes[1], h)
+
+	query := hashes[7]
+	query.P ^= 0xff
+	matches := tree.Search(query, 60, -1)
+
+	expected := 0
+	for _, hash := range hashes {
+		if hash.Distance(query) <= 60 {
+			expected++
+		}
+	}
+
+	assert.Equal(t, expected, len(matches))
+	assert.Equal(t, imaging.Match{ID: 7, Distance: 8}, matches[0])
+	for i := 1; i < len(matches); i++ {
+		assert.True(t, matches[i-1].Distance <= matches[i].Distance)
+	}
+
+	assert.Len(t, tree.Search(query, 60, 1), 1)
+}
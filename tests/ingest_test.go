@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"AT-BE/ingest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// reads every record of a fixture dump in tests/testdata/ingest
+func readDump(t *testing.T, name string) []ingest.Record {
+	var records []ingest.Record
+	err := ingest.Each(filepath.Join("testdata", "ingest", name), func(r ingest.Record) error {
+		records = append(records, r)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("[ERROR] Unable to read %v: %s", name, err)
+	}
+
+	return records
+}
+
+func TestEachCSV(t *testing.T) {
+	records := readDump(t, "artworks.csv")
+	if !assert.Len(t, records, 3) {
+		return
+	}
+
+	assert.Equal(t, 2, records[0].Line)
+	assert.Equal(t, "436535", records[0].Get("external_id"))
+	assert.Equal(t, `A field near Saint-Rémy, "painted in July"`, records[0].Get("description"))
+
+	// short rows are read with the missing fields left empty
+	assert.Equal(t, "", records[2].Get("medium"))
+	assert.Nil(t, records[2].Err)
+
+	// the byte order mark is not part of the first column name
+	sources := readDump(t, "sources.csv")
+	if assert.Len(t, sources, 2) {
+		assert.Equal(t, "met", sources[0].Get("abbreviation"))
+	}
+}
+
+func TestEachJSON(t *testing.T) {
+	records := readDump(t, "artworks.json")
+	if !assert.Len(t, records, 2) {
+		return
+	}
+
+	// numbers and nested values are kept as JSON text, nulls are left out
+	assert.Equal(t, "436535", records[0].Get("external_id"))
+	assert.JSONEq(t, `{"height": 73, "width": 93.4}`, records[0].Get("dimensions"))
+	_, set := records[1].Fields["image_small"]
+	assert.False(t, set)
+
+	lines := readDump(t, "artworks.jsonl")
+	if assert.Len(t, lines, 3) {
+		// blank lines are skipped but still counted
+		assert.Equal(t, 3, lines[1].Line)
+		assert.NotNil(t, lines[1].Err)
+		assert.Equal(t, 4, lines[2].Line)
+		assert.Equal(t, "pb-1", lines[2].Get("artist_external_id"))
+	}
+
+	err := ingest.Each("testdata/ingest/artworks.xml", func(ingest.Record) error { return nil })
+	assert.NotNil(t, err)
+}
+
+func TestParseRecords(t *testing.T) {
+	records := readDump(t, "artworks.csv")
+	if !assert.Len(t, records, 3) {
+		return
+	}
+
+	row, err := ingest.ParseArtwork(records[0])
+	assert.Nil(t, err)
+	assert.Equal(t, "436535", row.External_ID)
+	assert.Equal(t, "vg-1", row.Artist_External_ID)
+	assert.Equal(t, "1889", row.Artwork.Date_of_Release)
+	// without a thumbnail the full image is used
+	assert.Equal(t, row.Artwork.Image, row.Artwork.Image_Small)
+
+	_, err = ingest.ParseArtwork(records[1])
+	assert.EqualError(t, err, "title is required")
+	_, err = ingest.ParseArtwork(records[2])
+	assert.EqualError(t, err, "artist_external_id is required")
+
+	sources := readDump(t, "sources.csv")
+	if assert.Len(t, sources, 2) {
+		source, err := ingest.ParseSource(sources[0])
+		assert.Nil(t, err)
+		assert.Equal(t, "MET", source.Abriviation)
+		assert.Equal(t, "The Metropolitan Museum of Art", source.Source_Name)
+
+		_, err = ingest.ParseSource(sources[1])
+		assert.EqualError(t, err, "abbreviation is required")
+	}
+
+	artist, err := ingest.ParseArtist(ingest.Record{Fields: map[string]string{"id": " vg-1 ", "name": "Vincent van Gogh", "era": "Post-Impressionism"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "vg-1", artist.External_ID)
+	assert.Equal(t, "Post-Impressionism", artist.Artist.Era)
+
+	_, err = ingest.ParseEra(ingest.Record{Fields: map[string]string{"name": "  "}})
+	assert.EqualError(t, err, "name is required")
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"AT-BE/apierror"
+	"AT-BE/handlers"
+	"AT-BE/models"
+	"AT-BE/rankings"
+	"AT-BE/utils"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func rankingsRouter(db *gorm.DB) *gin.Engine {
+	router := gin.New()
+	router.SetTrustedProxies(nil)
+	router.GET("/artworks/popular", handlers.GetPopularArtworks(db))
+	router.GET("/artworks/trending", handlers.GetTrendingArtworks(db))
+	router.GET("/curations/trending", handlers.GetTrendingCurations(db))
+
+	return router
+}
+
+func TestDecay(t *testing.T) {
+	week := rankings.Windows["7d"]
+
+	assert.InDelta(t, 1, rankings.Decay(0, week), 1e-9)
+	assert.InDelta(t, 0.5, rankings.Decay(week/4, week), 1e-9)
+	assert.InDelta(t, 0.0625, rankings.Decay(week, week), 1e-9)
+
+	// a like from a clock slightly ahead is not worth more than a new one
+	assert.InDelta(t, 1, rankings.Decay(-time.Minute, week), 1e-9)
+
+	// the same age decays faster in a shorter window
+	assert.Less(t, rankings.Decay(12*time.Hour, rankings.Windows["24h"]), rankings.Decay(12*time.Hour, week))
+}
+
+// unknown windows and malformed limits are rejected before the database is queried
+func TestTrendingValidation(t *testing.T) {
+	router := rankingsRouter(nil)
+
+	for _, path := range []string{
+		"/artworks/trending?window=1y",
+		"/curations/trending?window=week",
+		"/artworks/trending?limit=ten",
+		"/artworks/popular?limit=ten",
+	} {
+		writer := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(writer, req)
+
+		assert.Equal(t, 400, writer.Code, path)
+		assert.Equal(t, apierror.InvalidParam, decodeError(t, writer).Code, path)
+	}
+}
+
+func TestRankings(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	if err := rankings.Refresh(db, time.Now()); err != nil {
+		t.Errorf("[ERROR] Unable to refresh rankings: %s", err)
+	}
+
+	router := rankingsRouter(db)
+
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/artworks/popular?limit=5", nil)
+	router.ServeHTTP(writer, req)
+	assert.Equal(t, 200, writer.Code)
+
+	var popular models.RankingList
+	if err := json.Unmarshal(writer.Body.Bytes(), &popular); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to popular: %s", err)
+	}
+
+	assert.Equal(t, rankings.AllTime, popular.Period)
+	assert.LessOrEqual(t, len(popular.Artworks), 5)
+	for i := 1; i < len(popular.Artworks); i++ {
+		assert.GreaterOrEqual(t, popular.Artworks[i-1].Likes, popular.Artworks[i].Likes)
+	}
+
+	for _, path := range []string{"/artworks/trending?window=24h", "/curations/trending?window=30d"} {
+		writer = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(writer, req)
+		assert.Equal(t, 200, writer.Code, path)
+
+		var trending models.RankingList
+		if err := json.Unmarshal(writer.Body.Bytes(), &trending); err != nil {
+			t.Errorf("[ERROR] Unable to unmarshal data to trending: %s", err)
+		}
+
+		for i := 1; i < len(trending.Artworks); i++ {
+			assert.GreaterOrEqual(t, trending.Artworks[i-1].Score, trending.Artworks[i].Score)
+		}
+		for i := 1; i < len(trending.Curations); i++ {
+			assert.GreaterOrEqual(t, trending.Curations[i-1].Score, trending.Curations[i].Score)
+		}
+	}
+}
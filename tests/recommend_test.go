@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"AT-BE/recommend"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// users 1-3 like the Dutch works 10, 11 and 12 together, users 4 and 5 like 20 and 21
+var sampleLikes = recommend.Likes{
+	1: {10, 11, 12},
+	2: {10, 11, 12},
+	3: {10, 11},
+	4: {20, 21},
+	5: {20, 21, 10},
+}
+
+func TestBuildModel(t *testing.T) {
+	model := recommend.Build(sampleLikes, 2)
+
+	// 10 and 11 are liked by the same three users of the four who like 10
+	if assert.Equal(t, 2, len(model[10])) {
+		assert.Equal(t, 11, model[10][0].ID)
+		assert.InDelta(t, 3/2.0/1.7320508, model[10][0].Score, 1e-6)
+	}
+
+	for _, list := range model {
+		assert.LessOrEqual(t, len(list), 2)
+		for i := 1; i < len(list); i++ {
+			assert.GreaterOrEqual(t, list[i-1].Score, list[i].Score)
+		}
+	}
+
+	// works nobody likes alongside another have no neighbours
+	assert.Empty(t, recommend.Build(recommend.Likes{1: {5}, 2: {6, 6}}, 10))
+}
+
+func TestRecommend(t *testing.T) {
+	model := recommend.Build(sampleLikes, 10)
+
+	recs := model.Recommend([]int{10, 11}, 3)
+	if assert.NotEmpty(t, recs) {
+		assert.Equal(t, 12, recs[0].ID)
+	}
+	for _, rec := range recs {
+		assert.NotEqual(t, 10, rec.ID)
+		assert.NotEqual(t, 11, rec.ID)
+	}
+
+	assert.Empty(t, model.Recommend(nil, 3))
+	assert.Len(t, model.Recommend([]int{10}, 1), 1)
+}
+
+func TestSplitAndEvaluate(t *testing.T) {
+	train, test := recommend.Split(sampleLikes, 0.5, rand.New(rand.NewSource(1)))
+
+	for user, likes := range sampleLikes {
+		assert.Equal(t, len(likes), len(train[user])+len(test[user]))
+		assert.NotEmpty(t, train[user])
+	}
+
+	// the split only depends on the seed
+	again, _ := recommend.Split(sampleLikes, 0.5, rand.New(rand.NewSource(1)))
+	assert.Equal(t, train, again)
+
+	eval := recommend.Evaluate(train, test, 2, 10)
+	assert.Equal(t, len(test), eval.Users)
+	assert.True(t, eval.Precision >= 0 && eval.Precision <= 1)
+	assert.True(t, eval.Recall >= 0 && eval.Recall <= 1)
+
+	// holding out 12 from user 1, whose other likes are always liked with it, is a hit
+	eval = recommend.Evaluate(recommend.Likes{1: {10, 11}, 2: {10, 11, 12}, 3: {10, 11, 12}}, recommend.Likes{1: {12}}, 1, 10)
+	assert.Equal(t, 1, eval.Covered)
+	assert.InDelta(t, 1, eval.Precision, 1e-9)
+	assert.InDelta(t, 1, eval.Recall, 1e-9)
+}
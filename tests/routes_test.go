@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"AT-BE/apierror"
 	"AT-BE/handlers"
 	m "AT-BE/middleware"
 	"AT-BE/models"
@@ -11,10 +12,15 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
 // POST and GET methods currently available
@@ -296,14 +302,16 @@ func TestUsers(t *testing.T) {
 		t.Errorf("[Error] Unable to read writer.Body: %s", err)
 	}
 
-	var res utils.ParsedUserRequestData
+	var res models.Profile
 	if err := json.Unmarshal(wb, &res); err != nil {
 		t.Errorf("[ERROR] Unable to unmarshal data to jsonData: %s", err)
 	}
 
-	assert.True(t, res.Email == "sample@gmail.com")
-	assert.True(t, res.Username == "sampleUser")
-	assert.True(t, res.Password == "")
+	assert.Equal(t, uint(16), res.ID)
+	assert.Equal(t, "sampleUser", res.Username)
+	// only the profile is returned, never the email or settings
+	assert.NotContains(t, string(wb), "sample@gmail.com")
+	assert.NotContains(t, string(wb), "show_likes")
 }
 
 func TestAuthenticateUser(t *testing.T) {
@@ -432,6 +440,32 @@ func TestRegisterUser(t *testing.T) {
 	db.Unscoped().Delete(&u)
 }
 
+// malformed bodies and short passwords are rejected before the db is used
+func TestRegisterUserInvalid(t *testing.T) {
+	router := setupGetRouter(handlers.RegisterUser(nil), "/sign-up", "POST")
+
+	cases := map[string]string{
+		`{"username": "tester123", "password": "short"}`: "Password length is too short, must be 8 or more characters",
+		`{"username": `: "unable to unmarshal data: : unexpected end of JSON input",
+	}
+
+	for body, message := range cases {
+		writer := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/sign-up", strings.NewReader(body))
+		router.ServeHTTP(writer, req)
+
+		assert.Equal(t, 400, writer.Code, body)
+
+		var msg apierror.Error
+		if err := json.Unmarshal(writer.Body.Bytes(), &msg); err != nil {
+			t.Errorf("[ERROR] Unable to unmarshal data to msg: %s", err)
+		}
+
+		assert.Equal(t, apierror.InvalidParam, msg.Code, body)
+		assert.Equal(t, message, msg.Message, body)
+	}
+}
+
 // tests fetching a true like and a nil like
 func TestCheckArtworkLikes(t *testing.T) {
 	db, _, err := utils.SetupConfiguration(true)
@@ -630,11 +664,15 @@ func TestSearch(t *testing.T) {
 		t.Errorf("[Error] Unable to read writer.Body: %s", err)
 	}
 
-	var searches []models.Searches
-	if err := json.Unmarshal(data, &searches); err != nil {
-		t.Errorf("[ERROR] Unable to unmarshal data to searches: %s", err)
+	var response models.SearchResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to response: %s", err)
 	}
 
+	searches := response.Results
+	assert.Contains(t, response.Facets, "source")
+	assert.Contains(t, response.Facets, "century")
+
 	assert.True(t, true, len(searches) > 1)
 	assert.Nil(t, nil, searches[0].IMG_S)
 	assert.True(t, true, searches[0].ID == "22")
@@ -777,12 +815,14 @@ func TestGetArtworks(t *testing.T) {
 		t.Errorf("\u001b[31m[Error] Unable to read writer.Body: %s", err)
 	}
 
-	var artworks []models.Artwork
-	if err := json.Unmarshal(data, &artworks); err != nil {
-		t.Errorf("\u001b[31m[ERROR] Unable to unmarshal data to artworks: %s", err)
+	var page models.ArtworkPage
+	if err := json.Unmarshal(data, &page); err != nil {
+		t.Errorf("\u001b[31m[ERROR] Unable to unmarshal data to page: %s", err)
 	}
 
+	artworks := page.Artworks
 	assert.Equal(t, 10, len(artworks))
+	assert.NotEqual(t, "", page.NextCursor)
 
 	a0, a2, a5, a7 := artworks[0], artworks[2], artworks[5], artworks[7]
 	assert.Equal(t, a0.Title, "Portrait of Leonardus van der Voort")
@@ -790,3 +830,1504 @@ func TestGetArtworks(t *testing.T) {
 	assert.Equal(t, a5.Title, "The harbor entrance of Willemstad with the Government Palace")
 	assert.Equal(t, a7.Title, "Heemskerck and Barents prepare their second expedition to the North")
 }
+
+// logs in as sampleUser and returns the jwt cookie for routes behind m.Authenticate
+func loginCookie(t *testing.T, db *gorm.DB) *http.Cookie {
+	router := setupGetRouter(handlers.LoginUser(db), "/login", "POST")
+	writer := httptest.NewRecorder()
+
+	loginReq := utils.ParsedUserRequestData{
+		Username: "sampleUser",
+		Password: "sampleUser",
+	}
+
+	marshalledData, err := json.Marshal(loginReq)
+	if err != nil {
+		t.Error(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(marshalledData))
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	for _, cookie := range writer.Result().Cookies() {
+		if cookie.Name == "jwt" {
+			return cookie
+		}
+	}
+
+	t.Fatal("[Error] login did not set a jwt cookie")
+	return nil
+}
+
+// sampleUser follows user 2, checks the follower list, then unfollows
+func TestFollowUser(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	cookie := loginCookie(t, db)
+
+	router := gin.New()
+	router.SetTrustedProxies(nil)
+	router.POST("/users/:id/follow", m.Authenticate, handlers.FollowUser(db))
+	router.POST("/users/:id/unfollow", m.Authenticate, handlers.UnfollowUser(db))
+	router.GET("/users/:id/followers", handlers.GetFollowers(db))
+
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/users/2/follow", nil)
+	req.AddCookie(cookie)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 201, writer.Code)
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/users/2/followers", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	wb, err := ioutil.ReadAll(writer.Body)
+	if err != nil {
+		t.Errorf("[Error] Unable to read writer.Body: %s", err)
+	}
+
+	var list models.FollowList
+	if err := json.Unmarshal(wb, &list); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to list: %s", err)
+	}
+
+	assert.True(t, list.Count >= 1)
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/users/2/unfollow", nil)
+	req.AddCookie(cookie)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 202, writer.Code)
+
+	// following yourself and unauthenticated follows are rejected
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/users/16/follow", nil)
+	req.AddCookie(cookie)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 400, writer.Code)
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/users/2/follow", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 401, writer.Code)
+}
+
+// followed users and curations are counted apart, and a followed curation that has since
+// been made private is neither listed nor counted
+func TestGetFollowing(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	curations := []models.Curations{
+		{User_ID: 2, Name: "-*-public followed cpadgett-*-"},
+		{User_ID: 2, Name: "-*-private followed cpadgett-*-", Private: true},
+	}
+	if err := db.Create(&curations).Error; err != nil {
+		t.Fatalf("[ERROR] Unable to create curations: %s", err)
+	}
+	defer db.Unscoped().Delete(&curations)
+
+	follows := []models.Follows{
+		{Follower_ID: 16, Curation_ID: curations[0].ID},
+		{Follower_ID: 16, Curation_ID: curations[1].ID},
+	}
+	if err := db.Create(&follows).Error; err != nil {
+		t.Fatalf("[ERROR] Unable to create follows: %s", err)
+	}
+	defer db.Unscoped().Delete(&follows)
+
+	var userFollows, curationFollows int64
+	db.Model(&models.Follows{}).Where("follower_id = 16 and followed_id <> 0").Count(&userFollows)
+	db.Table("follows").Joins("join curations on curations.id = follows.curation_id").Where(
+		"follows.follower_id = 16 and follows.deleted_at is null and curations.private = false and curations.deleted_at is null").Count(&curationFollows)
+
+	router := setupGetRouter(handlers.GetFollowing(db), "/users/:id/following", "GET")
+
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users/16/following", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	var list models.FollowList
+	if err := json.Unmarshal(writer.Body.Bytes(), &list); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to list: %s", err)
+	}
+
+	assert.Equal(t, userFollows, list.Count)
+	assert.Equal(t, curationFollows, list.Curation_Count)
+
+	ids := []uint{}
+	for _, cur := range list.Curations {
+		ids = append(ids, cur.ID)
+	}
+	assert.Contains(t, ids, curations[0].ID)
+	assert.NotContains(t, ids, curations[1].ID)
+}
+
+func TestGetFeed(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	cookie := loginCookie(t, db)
+
+	router := gin.New()
+	router.SetTrustedProxies(nil)
+	router.GET("/feed", m.Authenticate, handlers.GetFeed(db))
+
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/feed", nil)
+	req.AddCookie(cookie)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	wb, err := ioutil.ReadAll(writer.Body)
+	if err != nil {
+		t.Errorf("[Error] Unable to read writer.Body: %s", err)
+	}
+
+	var feed models.Feed
+	if err := json.Unmarshal(wb, &feed); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to feed: %s", err)
+	}
+
+	for i := 1; i < len(feed.Events); i++ {
+		assert.True(t, feed.Events[i-1].ID > feed.Events[i].ID)
+	}
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/feed?cursor=abc", nil)
+	req.AddCookie(cookie)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 400, writer.Code)
+}
+
+// sampleUser adds an artwork to their curation, which is recorded for the feed of the
+// curation's likers, and cannot add it twice
+func TestAddCurationArtwork(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	cookie := loginCookie(t, db)
+
+	cur := models.Curations{User_ID: 16, Name: "-*-add artwork cpadgett-*-"}
+	if err := db.Create(&cur).Error; err != nil {
+		t.Fatalf("[ERROR] Unable to create curation: %s", err)
+	}
+	defer db.Unscoped().Delete(&cur)
+	defer db.Unscoped().Where("curation_id = ?", cur.ID).Delete(&models.Activity{})
+
+	router := gin.New()
+	router.SetTrustedProxies(nil)
+	router.POST("/curation/:id/artworks", m.Authenticate, handlers.AddCurationArtwork(db))
+
+	route := "/curation/" + strconv.Itoa(int(cur.ID)) + "/artworks"
+	add := func() *httptest.ResponseRecorder {
+		writer := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, route, strings.NewReader(`{"artworkID": 22}`))
+		req.AddCookie(cookie)
+		router.ServeHTTP(writer, req)
+
+		return writer
+	}
+
+	writer := add()
+	assert.Equal(t, 201, writer.Code)
+
+	var updated models.Curations
+	if err := json.Unmarshal(writer.Body.Bytes(), &updated); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to updated: %s", err)
+	}
+	assert.Len(t, updated.Artworks, 1)
+
+	var activities int64
+	db.Model(&models.Activity{}).Where(
+		"curation_id = ? and verb = ? and artwork_id = 22", cur.ID, models.VerbCurationArtworkAdded).Count(&activities)
+	assert.Equal(t, int64(1), activities)
+
+	writer = add()
+	assert.Equal(t, 409, writer.Code)
+}
+
+func TestNotificationPrefs(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	cookie := loginCookie(t, db)
+
+	router := gin.New()
+	router.SetTrustedProxies(nil)
+	router.GET("/notifications/unread", m.Authenticate, handlers.UnreadNotificationCount(db))
+	router.GET("/notifications/preferences", m.Authenticate, handlers.GetNotificationPrefs(db))
+	router.POST("/notifications/preferences", m.Authenticate, handlers.UpdateNotificationPref(db))
+
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/notifications/unread", nil)
+	req.AddCookie(cookie)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	for _, muted := range []bool{true, false} {
+		marshalledData, err := json.Marshal(models.UpdateNotificationPref{
+			Category: models.NotifyCurationLiked,
+			Muted:    muted,
+		})
+		if err != nil {
+			t.Error(err)
+		}
+
+		writer = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPost, "/notifications/preferences", bytes.NewReader(marshalledData))
+		req.AddCookie(cookie)
+		router.ServeHTTP(writer, req)
+
+		assert.Equal(t, 202, writer.Code)
+
+		writer = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, "/notifications/preferences", nil)
+		req.AddCookie(cookie)
+		router.ServeHTTP(writer, req)
+
+		var prefs map[string]bool
+		if err := json.Unmarshal(writer.Body.Bytes(), &prefs); err != nil {
+			t.Errorf("[ERROR] Unable to unmarshal data to prefs: %s", err)
+		}
+
+		assert.Equal(t, muted, prefs[models.NotifyCurationLiked])
+	}
+
+	// categories nothing sends yet cannot be configured
+	for _, category := range []string{"not a category", "curation_forked", "collaboration_invite"} {
+		marshalledData, err := json.Marshal(models.UpdateNotificationPref{Category: category})
+		if err != nil {
+			t.Error(err)
+		}
+
+		writer = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPost, "/notifications/preferences", bytes.NewReader(marshalledData))
+		req.AddCookie(cookie)
+		router.ServeHTTP(writer, req)
+
+		assert.Equal(t, 400, writer.Code, category)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	cookie := loginCookie(t, db)
+
+	router := gin.New()
+	router.SetTrustedProxies(nil)
+	router.GET("/users/:id", handlers.GetProfile(db))
+	router.POST("/users/profile", m.Authenticate, handlers.UpdateProfileHandler(db))
+
+	bio := "-*-test bio cpadgett-*-"
+	marshalledData, err := json.Marshal(models.UpdateProfile{Bio: &bio})
+	if err != nil {
+		t.Error(err)
+	}
+
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/users/profile", bytes.NewReader(marshalledData))
+	req.AddCookie(cookie)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 202, writer.Code)
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/users/sampleUser", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	var profile models.Profile
+	if err := json.Unmarshal(writer.Body.Bytes(), &profile); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to profile: %s", err)
+	}
+
+	assert.Equal(t, "sampleUser", profile.Username)
+	assert.Equal(t, bio, profile.Bio)
+	assert.NotContains(t, writer.Body.String(), "sample@gmail.com")
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/users/-no-such-user-cpadgett-", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 404, writer.Code)
+}
+
+func TestBlockAndReport(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	cookie := loginCookie(t, db)
+
+	router := gin.New()
+	router.SetTrustedProxies(nil)
+	router.POST("/users/:id/block", m.Authenticate, handlers.BlockUser(db))
+	router.POST("/users/:id/unblock", m.Authenticate, handlers.UnblockUser(db))
+	router.GET("/blocks", m.Authenticate, handlers.GetBlocks(db))
+	router.POST("/report", m.Authenticate, handlers.ReportHandler(db))
+	router.GET("/admin/reports", m.Authenticate, m.RequireAdmin(db), handlers.GetReports(db))
+
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/users/2/block", nil)
+	req.AddCookie(cookie)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 201, writer.Code)
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/blocks", nil)
+	req.AddCookie(cookie)
+	router.ServeHTTP(writer, req)
+
+	var blocked []models.PublicUser
+	if err := json.Unmarshal(writer.Body.Bytes(), &blocked); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to blocked: %s", err)
+	}
+
+	found := false
+	for _, u := range blocked {
+		found = found || u.ID == 2
+	}
+	assert.True(t, found)
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/users/2/unblock", nil)
+	req.AddCookie(cookie)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 202, writer.Code)
+
+	// a report needs a reason and exactly one of userID and curationID
+	marshalledData, err := json.Marshal(models.NewReportReq{UserID: 2, CurationID: 1, Reason: "spam"})
+	if err != nil {
+		t.Error(err)
+	}
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/report", bytes.NewReader(marshalledData))
+	req.AddCookie(cookie)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 400, writer.Code)
+
+	// sampleUser is not an admin
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/admin/reports", nil)
+	req.AddCookie(cookie)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 403, writer.Code)
+}
+
+// sampleUser blocks user 2, whose public curation then drops out of their profile for
+// sampleUser but not for anonymous callers
+func TestBlockHidesCurations(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	cookie := loginCookie(t, db)
+
+	var owner models.Users
+	if err := db.Take(&owner, 2).Error; err != nil {
+		t.Fatalf("[ERROR] Unable to load user 2: %s", err)
+	}
+	if owner.Private_Profile {
+		t.Skip("user 2 has a private profile, which never lists curations")
+	}
+
+	cur := models.Curations{User_ID: 2, Name: "-*-blocked curation cpadgett-*-"}
+	if err := db.Create(&cur).Error; err != nil {
+		t.Fatalf("[ERROR] Unable to create curation: %s", err)
+	}
+	defer db.Unscoped().Delete(&cur)
+
+	router := gin.New()
+	router.SetTrustedProxies(nil)
+	router.GET("/users/:id", m.OptionalAuthenticate, handlers.GetProfile(db))
+	router.POST("/users/:id/block", m.Authenticate, handlers.BlockUser(db))
+	router.POST("/users/:id/unblock", m.Authenticate, handlers.UnblockUser(db))
+
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/users/2/block", nil)
+	req.AddCookie(cookie)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 201, writer.Code)
+
+	defer func() {
+		req := httptest.NewRequest(http.MethodPost, "/users/2/unblock", nil)
+		req.AddCookie(cookie)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+
+	listed := func(withCookie bool) bool {
+		writer := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/users/"+owner.Username, nil)
+		if withCookie {
+			req.AddCookie(cookie)
+		}
+		router.ServeHTTP(writer, req)
+
+		assert.Equal(t, 200, writer.Code)
+
+		var profile models.Profile
+		if err := json.Unmarshal(writer.Body.Bytes(), &profile); err != nil {
+			t.Errorf("[ERROR] Unable to unmarshal data to profile: %s", err)
+		}
+
+		for _, listed := range profile.Curations {
+			if listed.ID == cur.ID {
+				return true
+			}
+		}
+		return false
+	}
+
+	assert.False(t, listed(true))
+	assert.True(t, listed(false))
+}
+
+func TestBrowseArtworks(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	route := "/artworks/"
+	handler := handlers.GetArtworks(db)
+	router := setupGetRouter(handler, route, "GET")
+
+	// the second page starts after the last artwork of the first
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/artworks/?limit=5&sort=title&order=desc", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	var first models.ArtworkPage
+	if err := json.Unmarshal(writer.Body.Bytes(), &first); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to first: %s", err)
+	}
+
+	assert.Equal(t, 5, len(first.Artworks))
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/artworks/?limit=5&sort=title&order=desc&cursor="+first.NextCursor, nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	var second models.ArtworkPage
+	if err := json.Unmarshal(writer.Body.Bytes(), &second); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to second: %s", err)
+	}
+
+	if assert.True(t, len(second.Artworks) > 0) {
+		assert.True(t, second.Artworks[0].Title <= first.Artworks[4].Title)
+		assert.NotEqual(t, first.Artworks[4].ID, second.Artworks[0].ID)
+	}
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/artworks/?source_id=5&date_from=1800&date_to=1900", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	// wildcards in a filter only match themselves
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/artworks/?medium="+url.QueryEscape("%_"), nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	var literal models.ArtworkPage
+	if err := json.Unmarshal(writer.Body.Bytes(), &literal); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to literal: %s", err)
+	}
+
+	for _, artwork := range literal.Artworks {
+		assert.Contains(t, artwork.Medium, "%_")
+	}
+
+	for _, bad := range []string{"sort=colour", "order=up", "artist_id=abc", "cursor=notacursor!"} {
+		writer = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, "/artworks/?"+bad, nil)
+		router.ServeHTTP(writer, req)
+
+		assert.Equal(t, 400, writer.Code, bad)
+	}
+}
+
+// an artwork without a last_modified sorts as the epoch instead of ending the pages early
+func TestBrowseNullLastModified(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	var artwork models.Artwork
+	if err := db.Take(&artwork, 22).Error; err != nil {
+		t.Fatalf("[ERROR] Unable to load artwork 22: %s", err)
+	}
+	artwork.ID = 0
+	artwork.Title = "-*-no last modified cpadgett-*-"
+	if err := db.Create(&artwork).Error; err != nil {
+		t.Fatalf("[ERROR] Unable to create artwork: %s", err)
+	}
+	defer db.Delete(&artwork)
+
+	if err := db.Exec("update artwork_migrate_artwork set last_modified = null where id = ?", artwork.ID).Error; err != nil {
+		t.Fatalf("[ERROR] Unable to clear last_modified: %s", err)
+	}
+
+	router := setupGetRouter(handlers.GetArtworks(db), "/artworks/", "GET")
+
+	seen := map[int]bool{}
+	cursor := ""
+	for pages := 0; pages < 10 && !seen[artwork.ID]; pages++ {
+		writer := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/artworks/?sort=last_modified&limit=20&cursor="+url.QueryEscape(cursor), nil)
+		router.ServeHTTP(writer, req)
+
+		if !assert.Equal(t, 200, writer.Code) {
+			return
+		}
+
+		var page models.ArtworkPage
+		if err := json.Unmarshal(writer.Body.Bytes(), &page); err != nil {
+			t.Errorf("[ERROR] Unable to unmarshal data to page: %s", err)
+		}
+
+		for _, work := range page.Artworks {
+			assert.False(t, seen[work.ID], "artwork %v listed twice", work.ID)
+			seen[work.ID] = true
+		}
+
+		cursor = page.NextCursor
+		if cursor == "" {
+			break
+		}
+	}
+
+	assert.True(t, seen[artwork.ID])
+
+	// the page after the one holding the artwork still loads
+	if cursor != "" {
+		writer := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/artworks/?sort=last_modified&limit=20&cursor="+url.QueryEscape(cursor), nil)
+		router.ServeHTTP(writer, req)
+
+		assert.Equal(t, 200, writer.Code)
+
+		var page models.ArtworkPage
+		if err := json.Unmarshal(writer.Body.Bytes(), &page); err != nil {
+			t.Errorf("[ERROR] Unable to unmarshal data to page: %s", err)
+		}
+		for _, work := range page.Artworks {
+			assert.False(t, seen[work.ID], "artwork %v listed twice", work.ID)
+		}
+	}
+}
+
+func TestSearchFacets(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	router := setupGetRouter(handlers.Search(db), "/search/:term", "GET")
+
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/search/jacob", nil)
+	router.ServeHTTP(writer, req)
+
+	var response models.SearchResponse
+	if err := json.Unmarshal(writer.Body.Bytes(), &response); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to response: %s", err)
+	}
+
+	if assert.True(t, len(response.Facets["source"]) > 0) {
+		source := response.Facets["source"][0]
+
+		writer = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, "/search/jacob?source="+url.QueryEscape(source.Value), nil)
+		router.ServeHTTP(writer, req)
+
+		assert.Equal(t, 200, writer.Code)
+
+		var filtered models.SearchResponse
+		if err := json.Unmarshal(writer.Body.Bytes(), &filtered); err != nil {
+			t.Errorf("[ERROR] Unable to unmarshal data to filtered: %s", err)
+		}
+
+		assert.Equal(t, source.Count, filtered.Total)
+		for _, s := range filtered.Results {
+			assert.Equal(t, source.Value, s.Abb)
+		}
+	}
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/search/jacob?century=nineteenth", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 400, writer.Code)
+}
+
+func TestSearchRanking(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	router := setupGetRouter(handlers.Search(db), "/search/:term", "GET")
+
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/search/portrait?limit=5", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	var response models.SearchResponse
+	if err := json.Unmarshal(writer.Body.Bytes(), &response); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to response: %s", err)
+	}
+
+	assert.True(t, len(response.Results) <= 5)
+	assert.True(t, response.Total >= int64(len(response.Results)))
+	assert.Equal(t, 5, response.NextPage)
+
+	for i := 1; i < len(response.Results); i++ {
+		assert.True(t, response.Results[i-1].Rank >= response.Results[i].Rank)
+	}
+
+	if len(response.Results) > 0 {
+		assert.Contains(t, response.Results[0].Headline, "<mark>")
+	}
+}
+
+// migrations must be repeatable, and the search documents they maintain cover medium
+func TestSearchDocuments(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	assert.Nil(t, models.Migrate(db))
+	assert.Nil(t, models.Migrate(db))
+
+	var missing int64
+	db.Table("artwork_migrate_artwork as a").Where(
+		"not exists (select 1 from search_documents as d where d.artwork_id = a.id)").Count(&missing)
+	assert.Equal(t, int64(0), missing)
+
+	router := setupGetRouter(handlers.Search(db), "/search/:term", "GET")
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/search/oil", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	var response models.SearchResponse
+	if err := json.Unmarshal(writer.Body.Bytes(), &response); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to response: %s", err)
+	}
+
+	assert.True(t, response.Total > 0)
+}
+
+// words only found in an artwork's description match it, and the description searched is the
+// one the result shows
+func TestSearchDescription(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	var artwork models.Artwork
+	if err := db.Take(&artwork, 22).Error; err != nil {
+		t.Fatalf("[ERROR] Unable to load artwork 22: %s", err)
+	}
+	artwork.ID = 0
+	artwork.Title = "-*-search description cpadgett-*-"
+	artwork.Desc = "a study of the cpadgettquillwort in morning light"
+	if err := db.Create(&artwork).Error; err != nil {
+		t.Fatalf("[ERROR] Unable to create artwork: %s", err)
+	}
+	defer db.Delete(&artwork)
+
+	router := setupGetRouter(handlers.Search(db), "/search/:term", "GET")
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/search/cpadgettquillwort", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	var response models.SearchResponse
+	if err := json.Unmarshal(writer.Body.Bytes(), &response); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to response: %s", err)
+	}
+
+	found := false
+	for _, hit := range response.Results {
+		if hit.ID == strconv.Itoa(artwork.ID) {
+			found = true
+			assert.Contains(t, hit.Description, "cpadgettquillwort")
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestSuggestAndFuzzySearch(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	router := gin.New()
+	router.SetTrustedProxies(nil)
+	router.GET("/search/:term", handlers.Search(db))
+	router.GET("/suggest", handlers.Suggest(db))
+
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/suggest?q=Ishiz", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	var suggestions []models.Suggestion
+	if err := json.Unmarshal(writer.Body.Bytes(), &suggestions); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to suggestions: %s", err)
+	}
+
+	if assert.True(t, len(suggestions) > 0) {
+		assert.Equal(t, "Ishizaki Yushi", suggestions[0].Value)
+		assert.Equal(t, "artist", suggestions[0].Kind)
+	}
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/suggest?q=I", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 400, writer.Code)
+
+	// misspelt artist name falls back to trigram matching
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/search/Ishizzaki", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	var response models.SearchResponse
+	if err := json.Unmarshal(writer.Body.Bytes(), &response); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to response: %s", err)
+	}
+
+	assert.True(t, response.Fuzzy)
+	assert.True(t, response.Total > 0)
+
+	// "suggest" is an ordinary search term
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/search/suggest", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	var searched models.SearchResponse
+	assert.Nil(t, json.Unmarshal(writer.Body.Bytes(), &searched))
+}
+
+func TestAdvancedSearch(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	router := setupGetRouter(handlers.Search(db), "/search/:term", "GET")
+
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/search/"+url.PathEscape(`artist:"Ishizaki Yushi" date:1800..1850`), nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	var response models.SearchResponse
+	if err := json.Unmarshal(writer.Body.Bytes(), &response); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to response: %s", err)
+	}
+
+	for _, hit := range response.Results {
+		assert.Equal(t, "Ishizaki Yushi", hit.Artist_Name)
+	}
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/search/"+url.PathEscape("painter:Monet"), nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 400, writer.Code)
+
+	var msg apierror.Error
+	if err := json.Unmarshal(writer.Body.Bytes(), &msg); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to msg: %s", err)
+	}
+
+	assert.Equal(t, apierror.InvalidQuery, msg.Code)
+	assert.Equal(t, "unknown field", msg.Message)
+	assert.Equal(t, "painter:", msg.Details["token"])
+}
+
+// colour search works alone and alongside a term, and every result is within colour_distance
+func TestColourSearch(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	router := gin.New()
+	router.SetTrustedProxies(nil)
+	router.GET("/search", handlers.Search(db))
+	router.GET("/search/:term", handlers.Search(db))
+
+	for _, path := range []string{"/search?colour=1e3a8a&colour_distance=30", "/search/portrait?colour=%231e3a8a&colour_distance=30"} {
+		writer := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(writer, req)
+
+		assert.Equal(t, 200, writer.Code, path)
+
+		var response models.SearchResponse
+		if err := json.Unmarshal(writer.Body.Bytes(), &response); err != nil {
+			t.Errorf("[ERROR] Unable to unmarshal data to response: %s", err)
+		}
+
+		for _, hit := range response.Results {
+			if assert.NotNil(t, hit.Colour_Distance, path) {
+				assert.True(t, *hit.Colour_Distance <= 30, path)
+			}
+		}
+	}
+
+	for _, path := range []string{"/search?colour=navy", "/search/portrait?colour=1e3a8a&colour_distance=-1", "/search"} {
+		writer := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(writer, req)
+
+		assert.Equal(t, 400, writer.Code, path)
+	}
+}
+
+// similar artworks exclude the artwork itself and come nearest first
+func TestSimilarArtworks(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	index, err := handlers.LoadSimilarityIndex(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := setupGetRouter(handlers.SimilarArtworks(db, index), "/artwork/:id/similar", "GET")
+
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/artwork/22/similar?limit=5", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	var similar []models.SimilarArtwork
+	if err := json.Unmarshal(writer.Body.Bytes(), &similar); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to similar: %s", err)
+	}
+
+	assert.True(t, len(similar) <= 5)
+	for i, artwork := range similar {
+		assert.NotEqual(t, "22", artwork.ID)
+		if i > 0 {
+			assert.True(t, similar[i-1].Distance <= artwork.Distance)
+		}
+	}
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/artwork/999999999/similar", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 404, writer.Code)
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/artwork/abc/similar", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 400, writer.Code)
+}
+
+// related groups are capped and never repeat an artwork or include the artwork itself
+func TestRelatedArtworks(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	router := setupGetRouter(handlers.GetRelatedArtworks(db), "/artwork/:id/related", "GET")
+
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/artwork/22/related?limit=3", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	var related models.RelatedArtworks
+	if err := json.Unmarshal(writer.Body.Bytes(), &related); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to related: %s", err)
+	}
+
+	seen := map[string]bool{"22": true}
+	for _, group := range [][]models.Searches{related.Artist, related.Curations, related.Era, related.Source} {
+		assert.True(t, len(group) <= 3)
+		for _, artwork := range group {
+			assert.False(t, seen[artwork.ID], artwork.ID)
+			seen[artwork.ID] = true
+		}
+	}
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/artwork/999999999/related", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 404, writer.Code)
+}
+
+// a public curation holding two works by different artists puts each in the other's
+// curations group
+func TestRelatedArtworksCoCurated(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	var first, second models.Artwork
+	if err := db.Take(&first, 22).Error; err != nil {
+		t.Fatalf("[ERROR] Unable to load artwork 22: %s", err)
+	}
+	if err := db.Where("artist_id <> ?", first.Artist_ID).Order("id").Take(&second).Error; err != nil {
+		t.Fatalf("[ERROR] Unable to load an artwork by another artist: %s", err)
+	}
+
+	items := []models.CurationArtwork{{Artwork_ID: first.ID, Order: 1}, {Artwork_ID: second.ID, Order: 2}}
+	if err := db.Create(&items).Error; err != nil {
+		t.Fatalf("[ERROR] Unable to create curation artwork: %s", err)
+	}
+	cur := models.Curations{User_ID: 1, Name: "co-curated", Artworks: models.IDArray{items[0].ID, items[1].ID}}
+	if err := db.Create(&cur).Error; err != nil {
+		t.Fatalf("[ERROR] Unable to create curation: %s", err)
+	}
+	defer db.Unscoped().Delete(&cur)
+	defer db.Unscoped().Delete(&items)
+
+	router := setupGetRouter(handlers.GetRelatedArtworks(db), "/artwork/:id/related", "GET")
+
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/artwork/22/related", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	var related models.RelatedArtworks
+	if err := json.Unmarshal(writer.Body.Bytes(), &related); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to related: %s", err)
+	}
+
+	if assert.NotEmpty(t, related.Curations) {
+		ids := []string{}
+		for _, artwork := range related.Curations {
+			ids = append(ids, artwork.ID)
+		}
+		assert.Contains(t, ids, strconv.Itoa(second.ID))
+	}
+}
+
+func TestGetArtists(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	router := gin.New()
+	router.SetTrustedProxies(nil)
+	router.GET("/artists", handlers.GetArtists(db))
+	router.GET("/artist/:id", handlers.GetArtist(db))
+
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/artists?letter=i&era=1817&limit=5", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	var list models.ArtistList
+	if err := json.Unmarshal(writer.Body.Bytes(), &list); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to list: %s", err)
+	}
+
+	assert.True(t, len(list.Artists) <= 5)
+	assert.True(t, list.Total >= int64(len(list.Artists)))
+	assert.Equal(t, 5, list.NextPage)
+	assert.NotEmpty(t, list.Index)
+	for _, artist := range list.Artists {
+		assert.Equal(t, "1817", artist.Era)
+		assert.Equal(t, "I", strings.ToUpper(artist.Name[:1]))
+	}
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/artists?letter=ab", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 400, writer.Code)
+
+	// wildcards in q only match themselves
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/artists?q="+url.QueryEscape("_%"), nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	var literal models.ArtistList
+	if err := json.Unmarshal(writer.Body.Bytes(), &literal); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to literal: %s", err)
+	}
+
+	for _, artist := range literal.Artists {
+		assert.Contains(t, artist.Name, "_%")
+	}
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/artist/5?limit=2", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	var detail models.ArtistDetail
+	if err := json.Unmarshal(writer.Body.Bytes(), &detail); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to detail: %s", err)
+	}
+
+	assert.Equal(t, "Ishizaki Yushi", detail.Name)
+	assert.True(t, len(detail.Works) <= 2)
+	assert.True(t, detail.Work_Count >= int64(len(detail.Works)))
+	for _, work := range detail.Works {
+		assert.Equal(t, 5, work.Artist_ID)
+	}
+	if detail.First_Year != nil && detail.Last_Year != nil {
+		assert.True(t, *detail.First_Year <= *detail.Last_Year)
+	}
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/artist/999999999", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 404, writer.Code)
+}
+
+func TestErasAndSources(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	router := gin.New()
+	router.SetTrustedProxies(nil)
+	router.GET("/eras", handlers.GetEras(db))
+	router.GET("/era/:id", handlers.GetEra(db))
+	router.GET("/sources", handlers.GetSources(db))
+	router.GET("/source/:id", handlers.GetSource(db))
+
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/eras", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	var eras []models.EraCount
+	if err := json.Unmarshal(writer.Body.Bytes(), &eras); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to eras: %s", err)
+	}
+	assert.NotEmpty(t, eras)
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/sources", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	var sources []models.SourceCount
+	if err := json.Unmarshal(writer.Body.Bytes(), &sources); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to sources: %s", err)
+	}
+	assert.NotEmpty(t, sources)
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/era/5?limit=3", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	var era models.EraDetail
+	if err := json.Unmarshal(writer.Body.Bytes(), &era); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to era: %s", err)
+	}
+
+	assert.Equal(t, "1817", era.Era_Name)
+	assert.True(t, len(era.Works) <= 3)
+	assert.True(t, era.Work_Count >= int64(len(era.Works)))
+	assert.True(t, len(era.Top_Artists) <= 5)
+	if era.Representative != nil {
+		assert.NotEqual(t, "", era.Representative.Image_Small)
+	}
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/source/5", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	var source models.SourceDetail
+	if err := json.Unmarshal(writer.Body.Bytes(), &source); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to source: %s", err)
+	}
+
+	assert.Equal(t, "Wilson L. Mead Fund", source.Source_Name)
+	for _, work := range source.Works {
+		assert.Equal(t, 5, work.Source_ID)
+	}
+
+	for _, path := range []string{"/era/999999999", "/source/999999999"} {
+		writer = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(writer, req)
+
+		assert.Equal(t, 404, writer.Code, path)
+	}
+}
+
+// builds a router over the catalog endpoints with the request ID middleware, as in main
+func catalogRouter(db *gorm.DB) *gin.Engine {
+	router := gin.New()
+	router.SetTrustedProxies(nil)
+	router.Use(apierror.RequestID)
+	router.NoRoute(apierror.NoRoute)
+	router.GET("/artwork/:id", handlers.GetArtwork(db))
+	router.GET("/artwork/:id/related", handlers.GetRelatedArtworks(db))
+	router.GET("/artist/:id", handlers.GetArtist(db))
+	router.GET("/era/:id", handlers.GetEra(db))
+	router.GET("/source/:id", handlers.GetSource(db))
+
+	return router
+}
+
+func decodeError(t *testing.T, writer *httptest.ResponseRecorder) apierror.Error {
+	var apiErr apierror.Error
+	if err := json.Unmarshal(writer.Body.Bytes(), &apiErr); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to apiErr: %s", err)
+	}
+
+	return apiErr
+}
+
+// malformed IDs are rejected before the database is queried, so no connection is needed
+func TestMalformedIDs(t *testing.T) {
+	router := catalogRouter(nil)
+
+	for _, path := range []string{"/artwork/abc", "/artwork/0/related", "/artist/-4", "/era/1.5", "/source/five"} {
+		writer := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(writer, req)
+
+		assert.Equal(t, 400, writer.Code, path)
+
+		apiErr := decodeError(t, writer)
+		assert.Equal(t, apierror.InvalidParam, apiErr.Code, path)
+		assert.Equal(t, "id must be a positive integer", apiErr.Message, path)
+		assert.NotNil(t, apiErr.Details["request_param"], path)
+		assert.NotEqual(t, "", apiErr.Request_ID, path)
+		assert.Equal(t, apiErr.Request_ID, writer.Header().Get(apierror.RequestIDHeader), path)
+	}
+
+	// a client's request ID is kept so that it can be found in the logs
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/artwork/abc", nil)
+	req.Header.Set(apierror.RequestIDHeader, "frontend-1234")
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, "frontend-1234", decodeError(t, writer).Request_ID)
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/no/such/route", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 404, writer.Code)
+	assert.Equal(t, apierror.NotFound, decodeError(t, writer).Code)
+}
+
+func TestMissingEntities(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	router := catalogRouter(db)
+
+	for _, path := range []string{"/artwork/999999999", "/artwork/999999999/related", "/artist/999999999", "/era/999999999", "/source/999999999"} {
+		writer := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(writer, req)
+
+		assert.Equal(t, 404, writer.Code, path)
+
+		apiErr := decodeError(t, writer)
+		assert.Equal(t, apierror.NotFound, apiErr.Code, path)
+		assert.Equal(t, "999999999", apiErr.Details["request_param"], path)
+		assert.NotEqual(t, "", apiErr.Request_ID, path)
+	}
+}
+
+func batchRouter(db *gorm.DB) *gin.Engine {
+	router := gin.New()
+	router.SetTrustedProxies(nil)
+	router.GET("/artworks/", handlers.GetArtworks(db))
+	router.GET("/artworks/batch", handlers.GetArtworkBatch(db))
+	router.POST("/artworks/batch", handlers.PostArtworkBatch(db))
+
+	return router
+}
+
+// bad batches are rejected before the database is queried
+func TestArtworkBatchValidation(t *testing.T) {
+	os.Setenv("batchlimit", "3")
+	defer os.Setenv("batchlimit", "")
+
+	router := batchRouter(nil)
+
+	for _, path := range []string{"/artworks/batch", "/artworks/batch?ids=1,two", "/artworks/batch?ids=1,-2", "/artworks/batch?ids=1,2,3,4"} {
+		writer := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(writer, req)
+
+		assert.Equal(t, 400, writer.Code, path)
+		assert.Equal(t, apierror.InvalidParam, decodeError(t, writer).Code, path)
+	}
+
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/artworks/batch", bytes.NewReader([]byte(`{"ids": [1, 2, 3, 4, 5]}`)))
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 400, writer.Code)
+	assert.Equal(t, float64(3), decodeError(t, writer).Details["max"])
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/artworks/batch", bytes.NewReader([]byte(`{"ids": "1,2"}`)))
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 400, writer.Code)
+}
+
+func TestArtworkBatch(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	router := batchRouter(db)
+
+	check := func(writer *httptest.ResponseRecorder) {
+		assert.Equal(t, 200, writer.Code)
+
+		var batch models.ArtworkBatch
+		if err := json.Unmarshal(writer.Body.Bytes(), &batch); err != nil {
+			t.Errorf("[ERROR] Unable to unmarshal data to batch: %s", err)
+		}
+
+		if assert.Equal(t, 2, len(batch.Artworks)) {
+			assert.Equal(t, "1015", batch.Artworks[0].ID)
+			assert.Equal(t, "22", batch.Artworks[1].ID)
+		}
+		assert.Equal(t, []int{999999999}, batch.Missing)
+	}
+
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/artworks/batch?ids=1015,999999999,22,1015", nil)
+	router.ServeHTTP(writer, req)
+	check(writer)
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/artworks/batch", bytes.NewReader([]byte(`{"ids": [1015, 999999999, 22]}`)))
+	router.ServeHTTP(writer, req)
+	check(writer)
+}
+
+func TestRandomAndDailyArtwork(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	router := gin.New()
+	router.SetTrustedProxies(nil)
+	router.GET("/artwork/random", handlers.GetRandomArtwork(db))
+	router.GET("/artwork/daily", handlers.GetDailyArtwork(db))
+	router.GET("/artwork/:id", handlers.GetArtwork(db))
+
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/artwork/random?source_id=1", nil)
+	router.ServeHTTP(writer, req)
+	assert.Equal(t, 200, writer.Code)
+
+	var artwork models.Searches
+	if err := json.Unmarshal(writer.Body.Bytes(), &artwork); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to artwork: %s", err)
+	}
+	assert.NotEmpty(t, artwork.IMG)
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/artwork/random?source_id=999999999", nil)
+	router.ServeHTTP(writer, req)
+	assert.Equal(t, 404, writer.Code)
+
+	var days [2]models.DailyArtwork
+	for i := range days {
+		writer = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, "/artwork/daily", nil)
+		router.ServeHTTP(writer, req)
+		assert.Equal(t, 200, writer.Code)
+
+		if err := json.Unmarshal(writer.Body.Bytes(), &days[i]); err != nil {
+			t.Errorf("[ERROR] Unable to unmarshal data to daily artwork: %s", err)
+		}
+	}
+
+	assert.NotEmpty(t, days[0].Artwork.IMG)
+	assert.Equal(t, days[0], days[1])
+}
+
+func TestRecommendations(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	cookie := loginCookie(t, db)
+
+	router := gin.New()
+	router.SetTrustedProxies(nil)
+	router.GET("/recommendations", m.Authenticate, handlers.GetRecommendations(db))
+
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/recommendations?limit=5", nil)
+	req.AddCookie(cookie)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	var recs []models.Recommendation
+	if err := json.Unmarshal(writer.Body.Bytes(), &recs); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to recs: %s", err)
+	}
+
+	assert.LessOrEqual(t, len(recs), 5)
+	seen := map[string]bool{}
+	for _, rec := range recs {
+		assert.False(t, seen[rec.ID])
+		seen[rec.ID] = true
+		assert.Contains(t, []string{models.ReasonLikedTogether, models.ReasonSimilarWorks, models.ReasonPopular}, rec.Reason)
+	}
+
+	writer = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/recommendations", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 401, writer.Code)
+}
+
+// a user without likes is recommended works similar to the ones in their curations
+func TestRecommendationsFromCurations(t *testing.T) {
+	db, _, err := utils.SetupConfiguration(true)
+	if err != nil {
+		t.Errorf("unable to setup db and env variables: %v", err)
+	}
+
+	user := models.Users{Username: "-*-no likes cpadgett-*-", Email: "nolikes@example.com"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("[ERROR] Unable to create user: %s", err)
+	}
+	defer db.Unscoped().Delete(&user)
+
+	item := models.CurationArtwork{Artwork_ID: 22, Order: 1}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatalf("[ERROR] Unable to create curation artwork: %s", err)
+	}
+	defer db.Unscoped().Delete(&item)
+
+	cur := models.Curations{User_ID: int(user.ID), Name: "cold start", Artworks: models.IDArray{item.ID}}
+	if err := db.Create(&cur).Error; err != nil {
+		t.Fatalf("[ERROR] Unable to create curation: %s", err)
+	}
+	defer db.Unscoped().Delete(&cur)
+
+	router := gin.New()
+	router.SetTrustedProxies(nil)
+	router.GET("/recommendations", func(c *gin.Context) {
+		c.Set("authID", int(user.ID))
+		c.Next()
+	}, handlers.GetRecommendations(db))
+
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/recommendations?limit=10", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, 200, writer.Code)
+
+	var recs []models.Recommendation
+	if err := json.Unmarshal(writer.Body.Bytes(), &recs); err != nil {
+		t.Errorf("[ERROR] Unable to unmarshal data to recs: %s", err)
+	}
+
+	similar := 0
+	for _, rec := range recs {
+		assert.NotEqual(t, "22", rec.ID)
+		if rec.Reason == models.ReasonSimilarWorks {
+			similar++
+		}
+	}
+	assert.True(t, similar > 0)
+}
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"AT-BE/search"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuery(t *testing.T) {
+	q, err := search.Parse(`artist:"Claude Monet" medium:oil date:1870..1890 -water "water lilies"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 5, len(q.Clauses))
+	assert.Equal(t, search.Match{Field: "artist", Value: "Claude Monet", Pos: 0}, q.Clauses[0])
+	assert.Equal(t, search.Match{Field: "medium", Value: "oil", Pos: 22}, q.Clauses[1])
+
+	r := q.Clauses[2].(search.Range)
+	assert.Equal(t, 1870, *r.From)
+	assert.Equal(t, 1890, *r.To)
+
+	assert.Equal(t, search.Text{Value: "water", Negated: true, Pos: 49}, q.Clauses[3])
+	assert.Equal(t, search.Text{Value: "water lilies", Phrase: true, Pos: 56}, q.Clauses[4])
+	assert.Equal(t, "water lilies", q.Text())
+
+	// open ended ranges and single years
+	q, err = search.Parse("date:..1700 -date:1650")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r = q.Clauses[0].(search.Range)
+	assert.Nil(t, r.From)
+	assert.Equal(t, 1700, *r.To)
+
+	r = q.Clauses[1].(search.Range)
+	assert.True(t, r.Negated)
+	assert.Equal(t, *r.From, *r.To)
+
+	// plain terms, including non-ASCII ones, are free text
+	q, err = search.Parse("Dürer 16:9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Dürer 16:9", q.Text())
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	cases := []struct {
+		input   string
+		pos     int
+		token   string
+		message string
+	}{
+		{"", 0, "", "query is empty"},
+		{"painter:Monet", 0, "painter:", "unknown field"},
+		{`artist:"Monet`, 7, `"Monet`, "unterminated quote"},
+		{"artist: oil", 0, "artist:", "missing value for artist"},
+		{"date:18th", 0, "date:18th", "expected a year"},
+		{"date:1900..1800", 0, "date:1900..1800", "range start is after its end"},
+		{"date:..", 0, "date:..", "range needs a start or an end"},
+		{"oil - water", 4, "-", "nothing to negate"},
+		{`wa"ter`, 2, `wa"`, "unexpected quote"},
+	}
+
+	for _, c := range cases {
+		_, err := search.Parse(c.input)
+		if !assert.NotNil(t, err, c.input) {
+			continue
+		}
+
+		syntaxErr := err.(*search.SyntaxError)
+		assert.Equal(t, c.pos, syntaxErr.Pos, c.input)
+		assert.Equal(t, c.token, syntaxErr.Token, c.input)
+		assert.Equal(t, c.message, syntaxErr.Message, c.input)
+	}
+}
+
+func TestQueryCondition(t *testing.T) {
+	q, err := search.Parse(`medium:50%_oil -era:1817 date:1870.. "water lilies"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sql, args := q.Condition("at_english")
+
+	assert.Equal(t, "(f_unaccent(a.medium) ilike f_unaccent(?)) and (not coalesce(f_unaccent(ar.era) ilike f_unaccent(?), false)) and "+
+		"(cast(substring(s.\"DOR\" from '[0-9]{4}') as int) >= ?) and "+
+		"(d.document @@ phraseto_tsquery(cast(? as regconfig), ?))", sql)
+	assert.Equal(t, []interface{}{`%50\%\_oil%`, "1817", 1870, "at_english", "water lilies"}, args)
+}
+
+func TestEscapeLike(t *testing.T) {
+	assert.Equal(t, `100\% \\ oil\_on`, search.EscapeLike(`100% \ oil_on`))
+	assert.Equal(t, "Dürer", search.EscapeLike("Dürer"))
+}
+
+func TestSearchLanguage(t *testing.T) {
+	cases := []struct {
+		param    string
+		header   string
+		language string
+	}{
+		{"", "", search.DefaultLanguage},
+		{"en", "", "english"},
+		{"Dutch", "", "dutch"},
+		{"", "fr-CA,en;q=0.8", "french"},
+		{"", "ja, de;q=0.7", "german"},
+		{"xx", "es-ES", "spanish"},
+		{"it", "de", "italian"},
+		{"xx", "ja", search.DefaultLanguage},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.language, search.Language(c.param, c.header), c.param+" / "+c.header)
+	}
+
+	assert.Equal(t, "at_english", search.Config("english"))
+}
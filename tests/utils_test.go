@@ -3,8 +3,10 @@ package tests
 import (
 	"AT-BE/models"
 	"AT-BE/utils"
+	"encoding/base64"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -82,3 +84,67 @@ func TestNextPage(t *testing.T) {
 	_, err = l.AddNextPage(0)
 	assert.True(t, err.Error() == "amt param cannot be less than or equal to 0")
 }
+
+func TestCursor(t *testing.T) {
+	cursor := utils.EncodeCursor("Portrait of Leonardus van der Voort", 42)
+
+	value, id, err := utils.DecodeCursor(cursor)
+	if err != nil {
+		t.Error(err)
+	}
+
+	assert.Equal(t, "Portrait of Leonardus van der Voort", value)
+	assert.Equal(t, 42, id)
+
+	_, _, err = utils.DecodeCursor("not a cursor")
+	assert.NotNil(t, err)
+
+	_, _, err = utils.DecodeCursor(base64.RawURLEncoding.EncodeToString([]byte(`{"v": 1}`)))
+	assert.NotNil(t, err)
+}
+
+func TestDay(t *testing.T) {
+	// 23:30 UTC is already the next day in Tokyo
+	now := time.Date(2023, time.March, 4, 23, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, "2023-03-04", utils.Day(now, "UTC"))
+	assert.Equal(t, "2023-03-05", utils.Day(now, "Asia/Tokyo"))
+	assert.Equal(t, "2023-03-04", utils.Day(now, ""))
+	assert.Equal(t, "2023-03-04", utils.Day(now, "Not/AZone"))
+}
+
+func TestDayIndex(t *testing.T) {
+	assert.Equal(t, utils.DayIndex("2023-03-04", 500), utils.DayIndex("2023-03-04", 500))
+	assert.Equal(t, 0, utils.DayIndex("2023-03-04", 0))
+
+	picks := map[int]bool{}
+	for day := 1; day <= 28; day++ {
+		date := time.Date(2023, time.February, day, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+
+		i := utils.DayIndex(date, 500)
+		assert.True(t, i >= 0 && i < 500)
+		picks[i] = true
+	}
+
+	// consecutive days should not keep landing on the same work
+	assert.Greater(t, len(picks), 20)
+}
+
+func TestIDArray(t *testing.T) {
+	value, err := models.IDArray{3, 14, 15}.Value()
+	assert.Nil(t, err)
+	assert.Equal(t, "{3,14,15}", value)
+
+	var ids models.IDArray
+	assert.Nil(t, ids.Scan([]byte("{3,14,15}")))
+	assert.Equal(t, models.IDArray{3, 14, 15}, ids)
+
+	assert.Nil(t, ids.Scan("{}"))
+	assert.Equal(t, models.IDArray{}, ids)
+
+	assert.Nil(t, ids.Scan(nil))
+	assert.Nil(t, ids)
+
+	assert.NotNil(t, ids.Scan("{1,x}"))
+	assert.NotNil(t, ids.Scan(42))
+}
@@ -1,11 +1,14 @@
 package utils
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
@@ -57,6 +60,13 @@ type Config struct {
 	Database  DatabaseConfig
 	SecretKey string
 	Origins   string
+	// "postgres" shares realtime events between instances through LISTEN/NOTIFY,
+	// anything else keeps them in process
+	Broker string
+	// most artworks returned by one batch lookup, see handlers.GetArtworkBatch
+	BatchLimit string
+	// how often the popularity and trending rankings are recomputed, e.g. "15m"
+	RankingInterval string
 }
 
 func (c *Config) SetUpViper(configFile, path, format string) error {
@@ -113,6 +123,18 @@ func (c *Config) SetUpViper(configFile, path, format string) error {
 		return errors.Wrap(err, "c.SecretKey: ")
 	}
 
+	if err := os.Setenv("broker", c.Broker); err != nil {
+		return errors.Wrap(err, "c.Broker: ")
+	}
+
+	if err := os.Setenv("batchlimit", c.BatchLimit); err != nil {
+		return errors.Wrap(err, "c.BatchLimit: ")
+	}
+
+	if err := os.Setenv("rankinginterval", c.RankingInterval); err != nil {
+		return errors.Wrap(err, "c.RankingInterval: ")
+	}
+
 	return nil
 }
 
@@ -130,6 +152,9 @@ func (c *Config) SetUpRailway() {
 
 	c.Origins = os.Getenv("origins")
 	c.SecretKey = os.Getenv("secretkey")
+	c.Broker = os.Getenv("broker")
+	c.BatchLimit = os.Getenv("batchlimit")
+	c.RankingInterval = os.Getenv("rankinginterval")
 }
 
 // Takes env variables and creates dsn for gorm database connection
@@ -195,3 +220,54 @@ func SetupConfiguration(test bool) (*gorm.DB, string, error) {
 
 	return db, config.Origins, nil
 }
+
+// Encodes the sort value and ID of the last row of a page into an opaque cursor for keyset pagination
+func EncodeCursor(value string, id int) string {
+	data, _ := json.Marshal([]interface{}{value, id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decodes a cursor created by EncodeCursor
+func DecodeCursor(cursor string) (string, int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", 0, errors.Wrap(err, "cursor is not valid base64")
+	}
+
+	var parts []interface{}
+	if err := json.Unmarshal(data, &parts); err != nil || len(parts) != 2 {
+		return "", 0, errors.New("cursor is malformed")
+	}
+
+	value, ok := parts[0].(string)
+	id, idOk := parts[1].(float64)
+	if !ok || !idOk {
+		return "", 0, errors.New("cursor is malformed")
+	}
+
+	return value, int(id), nil
+}
+
+// Returns the calendar day of now in timezone as YYYY-MM-DD, using UTC when timezone is empty
+// or unknown
+func Day(now time.Time, timezone string) string {
+	location, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "" {
+		location = time.UTC
+	}
+
+	return now.In(location).Format("2006-01-02")
+}
+
+// Picks an index below n from day, so every instance agrees on the pick for a day without
+// sharing state. Returns 0 when n is not positive
+func DayIndex(day string, n int) int {
+	if n <= 0 {
+		return 0
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(day))
+
+	return int(h.Sum64() % uint64(n))
+}